@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -11,7 +12,6 @@ import (
 	"github.com/weave-ai/weave-ai/pkg/utils"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -112,21 +112,15 @@ func activateModel(ctx context.Context, client runtimeclient.Client, namespace s
 	if waitFlag {
 		logger.Waitingf("waiting for model %s/%s to be active", namespace, name)
 
-		waitCtx, waitCancel := context.WithCancel(ctx)
-		wait.UntilWithContext(waitCtx, func(ctx context.Context) {
-			if err := client.Get(ctx, runtimeclient.ObjectKeyFromObject(model), model); err != nil {
-				return
-			}
-			if isActive(model) == false {
-				return
-			} else {
-				waitCancel()
-			}
-		}, 2*time.Second)
+		w, err := newWaiter(client)
+		if err != nil {
+			return err
+		}
+		if err := w.WaitForReady(ctx, model); err != nil {
+			return fmt.Errorf("model %s/%s is not active: %w", namespace, name, err)
+		}
 	}
 
-	// TODO if it's not ready after 5 minutes, return an error
-
 	return nil
 }
 
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/ssa"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+	"github.com/weave-ai/weave-ai/pkg/oci"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var addModelCmd = &cobra.Command{
+	Use:   "add-model [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Add a model OCI artifact to the model catalog",
+	Long: `Add a model OCI artifact, such as an in-house fine-tune, to the model catalog.
+The model is added suspended, activate it with activate-model when it's needed.
+Adding a model that exists already updates it and keeps it active if it was.
+
+# Add a model from a private registry
+weave-ai add-model my-llama --url oci://registry.example.com/models/my-llama --tag v1.0.0-q5km-gguf --secret-ref registry-auth
+
+# Add a model that follows the latest 1.x release
+weave-ai add-model my-llama --url oci://registry.example.com/models/my-llama --semver ">=1.0.0 <2.0.0"
+
+# Export the model to commit it to your own catalog kustomization
+weave-ai add-model my-llama --url oci://registry.example.com/models/my-llama --tag v1.0.0-q5km-gguf --family llama --context-length 4k --export > models/my-llama/ocirepo.yaml
+`,
+	RunE: addModelCmdRun,
+}
+
+var addModelFlags struct {
+	namespace     string
+	url           string
+	tag           string
+	semver        string
+	digest        string
+	secretRef     string
+	interval      time.Duration
+	family        string
+	contextLength string
+	parameters    string
+	license       string
+	activate      bool
+	export        bool
+}
+
+func init() {
+	addModelCmd.Flags().StringVarP(&addModelFlags.namespace, "namespace", "n", defaultNamespace, "namespace of the model catalog")
+	addModelCmd.Flags().StringVar(&addModelFlags.url, "url", "", "URL of the model OCI artifact, e.g. oci://ghcr.io/org/models/name")
+	addModelCmd.Flags().StringVar(&addModelFlags.tag, "tag", "", "tag of the model OCI artifact")
+	addModelCmd.Flags().StringVar(&addModelFlags.semver, "semver", "", "semver range the tag of the model OCI artifact has to match")
+	addModelCmd.Flags().StringVar(&addModelFlags.digest, "digest", "", "digest of the model OCI artifact")
+	addModelCmd.Flags().StringVar(&addModelFlags.secretRef, "secret-ref", "", "name of the docker-registry secret holding the credentials of the registry")
+	addModelCmd.Flags().DurationVar(&addModelFlags.interval, "interval", 10*time.Minute, "interval at which the model OCI artifact is checked for updates")
+	addModelCmd.Flags().StringVar(&addModelFlags.family, "family", "", "family of the model, e.g. llama or mistral")
+	addModelCmd.Flags().StringVar(&addModelFlags.contextLength, "context-length", "", "context length of the model, e.g. 4096 or 32k")
+	addModelCmd.Flags().StringVar(&addModelFlags.parameters, "parameters", "", "number of parameters of the model, e.g. 7B")
+	addModelCmd.Flags().StringVar(&addModelFlags.license, "license", "", "license of the model")
+	addModelCmd.Flags().BoolVar(&addModelFlags.activate, "activate", false, "add the model activated instead of suspended")
+	addModelCmd.Flags().BoolVar(&addModelFlags.export, "export", false, "export manifests instead of applying them")
+	addModelCmd.MarkFlagRequired("url")
+	rootCmd.AddCommand(addModelCmd)
+}
+
+func addModelCmdRun(cmd *cobra.Command, args []string) error {
+	model, err := newModelRepository(args[0])
+	if err != nil {
+		return err
+	}
+
+	if addModelFlags.export {
+		return exportObjects(os.Stdout, model)
+	}
+
+	var manifest bytes.Buffer
+	if err := exportObjects(&manifest, model); err != nil {
+		return err
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	manifests := manifest.Bytes()
+	if model.Spec.Suspend {
+		// updating the tag or the secret of a model must not suspend it
+		// under the LLMs using it
+		manifests, err = keepActivatedModels(ctx, manifests, false)
+		if err != nil {
+			return err
+		}
+		objs, err := ssa.ReadObjects(bytes.NewReader(manifests))
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			if suspend, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); !suspend {
+				model.Spec.Suspend = false
+			}
+		}
+	}
+
+	logger.Actionf("adding model %s/%s", model.Namespace, model.Name)
+	applyOutput, err := utils.Apply(ctx, kubeconfigArgs, kubeclientOptions, manifests, func(e ssa.ChangeSetEntry) bool {
+		// pulling the model can take much longer than the timeout
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, applyOutput)
+
+	if model.Spec.Suspend {
+		logger.Successf("model %s/%s added, run weave-ai activate-model %s/%s to use it", model.Namespace, model.Name, model.Namespace, model.Name)
+	} else {
+		logger.Successf("model %s/%s added and active", model.Namespace, model.Name)
+	}
+	return nil
+}
+
+// newModelRepository builds the OCIRepository of a model from the flags,
+// the same way the models of the catalog are written.
+func newModelRepository(name string) (*sourcev1b2.OCIRepository, error) {
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid model name %q: %s", name, strings.Join(errs, ", "))
+	}
+	if !strings.HasPrefix(addModelFlags.url, sourcev1b2.OCIRepositoryPrefix) {
+		return nil, fmt.Errorf("invalid URL %q, it must start with %s", addModelFlags.url, sourcev1b2.OCIRepositoryPrefix)
+	}
+
+	ref := &sourcev1b2.OCIRepositoryRef{
+		Tag:    addModelFlags.tag,
+		SemVer: addModelFlags.semver,
+		Digest: addModelFlags.digest,
+	}
+	set := 0
+	for _, v := range []string{ref.Tag, ref.SemVer, ref.Digest} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one of --tag, --semver or --digest is required")
+	}
+
+	model := &sourcev1b2.OCIRepository{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: sourcev1b2.GroupVersion.String(),
+			Kind:       sourcev1b2.OCIRepositoryKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: addModelFlags.namespace,
+			Labels: map[string]string{
+				artifactKindLabel: languageModelArtifactKind,
+			},
+		},
+		Spec: sourcev1b2.OCIRepositorySpec{
+			URL:       addModelFlags.url,
+			Reference: ref,
+			LayerSelector: &sourcev1b2.OCILayerSelector{
+				MediaType: oci.ContentMediaType,
+				Operation: sourcev1b2.OCILayerCopy,
+			},
+			Interval: metav1.Duration{Duration: addModelFlags.interval},
+			Suspend:  !addModelFlags.activate,
+		},
+	}
+	if addModelFlags.secretRef != "" {
+		model.Spec.SecretRef = &fluxmeta.LocalObjectReference{Name: addModelFlags.secretRef}
+	}
+
+	if addModelFlags.family != "" {
+		model.Labels[catalog.FamilyLabel] = addModelFlags.family
+	}
+	annotations, err := modelAnnotations(addModelFlags.contextLength, addModelFlags.parameters, addModelFlags.license)
+	if err != nil {
+		return nil, err
+	}
+	if len(annotations) > 0 {
+		model.Annotations = annotations
+	}
+
+	return model, nil
+}
+
+// modelAnnotations validates the descriptions of a model given on the
+// command line and returns them as the annotations read by the catalog index.
+func modelAnnotations(contextLength, parameters, license string) (map[string]string, error) {
+	annotations := map[string]string{}
+	if contextLength != "" {
+		n, err := catalog.ParseContextLength(contextLength)
+		if err != nil {
+			return nil, err
+		}
+		annotations[catalog.ContextLengthAnnotation] = fmt.Sprint(n)
+	}
+	if parameters != "" {
+		if _, err := catalog.ParseParameters(parameters); err != nil {
+			return nil, err
+		}
+		annotations[catalog.ParametersAnnotation] = parameters
+	}
+	if license != "" {
+		annotations[catalog.LicenseAnnotation] = license
+	}
+	return annotations, nil
+}
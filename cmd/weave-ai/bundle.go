@@ -0,0 +1,485 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fluxcd/pkg/ssa"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/cobra"
+	aiv1a1 "github.com/weave-ai/lm-controller/api/v1alpha1"
+	"github.com/weave-ai/weave-ai/models"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+	"github.com/weave-ai/weave-ai/pkg/oci"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Args:  cobra.NoArgs,
+	Short: "Create an offline install kit",
+	Long: `Write everything an install on a cluster without network access needs
+into one tar archive: the install manifests, the list of images, and an OCI
+layout holding the images and the artifacts of the chosen models.
+
+The kit holds:
+  manifests/   the lm-controller manifests and the catalog of the chosen models
+  images.txt   the images of the controller, the engine and the chat UI
+  oci/         an OCI layout of the images and the model artifacts
+
+# Create a kit with two models
+weave-ai bundle --models zephyr-7b-beta,tinyllama-1.1b-chat -o kit.tar
+
+# Load the kit into an internal registry, then install from the rewritten manifests
+weave-ai bundle load kit.tar --registry registry.internal/weave-ai
+weave-ai install --from-bundle ./weave-ai-bundle
+`,
+	RunE: bundleCmdRun,
+}
+
+var bundleFlags struct {
+	models   []string
+	output   string
+	platform string
+	images   []string
+	insecure bool
+}
+
+var bundleLoadCmd = &cobra.Command{
+	Use:   "load [kit]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Push the content of an offline install kit into a registry",
+	Long: `Push the images and the model artifacts of a kit into an internal
+registry, keeping their repository paths under it, and write the manifests
+of the kit with the model URLs and the controller image pointing at it.
+
+The lm-controller starts the engine with its own image references, mirror
+the engine image of images.txt in the container runtime of the nodes.
+
+# Load a kit and install from it
+weave-ai bundle load kit.tar --registry registry.internal/weave-ai -o ./weave-ai-bundle
+weave-ai install --from-bundle ./weave-ai-bundle
+`,
+	RunE: bundleLoadCmdRun,
+}
+
+var bundleLoadFlags struct {
+	registry  string
+	outputDir string
+	insecure  bool
+}
+
+const (
+	bundleImagesFile = "images.txt"
+	bundleLayoutDir  = "oci"
+)
+
+func init() {
+	bundleCmd.Flags().StringSliceVar(&bundleFlags.models, "models", nil, "catalog models to add to the kit, e.g. zephyr-7b-beta,tinyllama-1.1b-chat")
+	bundleCmd.Flags().StringVarP(&bundleFlags.output, "output", "o", "weave-ai-bundle.tar", "path of the kit")
+	bundleCmd.Flags().StringVar(&bundleFlags.platform, "platform", "linux/amd64", "platform of the images")
+	bundleCmd.Flags().StringArrayVar(&bundleFlags.images, "image", nil, "additional image to add to the kit, can be repeated")
+	bundleCmd.Flags().BoolVar(&bundleFlags.insecure, "insecure", false, "allow plain HTTP registries")
+	bundleCmd.MarkFlagRequired("models")
+
+	bundleLoadCmd.Flags().StringVar(&bundleLoadFlags.registry, "registry", "", "registry to push to, e.g. registry.internal/weave-ai")
+	bundleLoadCmd.Flags().StringVarP(&bundleLoadFlags.outputDir, "output-dir", "o", "weave-ai-bundle", "directory to write the rewritten manifests to")
+	bundleLoadCmd.Flags().BoolVar(&bundleLoadFlags.insecure, "insecure", false, "allow a plain HTTP registry")
+	bundleLoadCmd.MarkFlagRequired("registry")
+
+	bundleCmd.AddCommand(bundleLoadCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
+
+func bundleCmdRun(cmd *cobra.Command, args []string) error {
+	platform, err := v1.ParsePlatform(bundleFlags.platform)
+	if err != nil {
+		return fmt.Errorf("invalid platform %q: %w", bundleFlags.platform, err)
+	}
+
+	catalogModels, err := catalog.Load(models.FS)
+	if err != nil {
+		return err
+	}
+	var chosen []*sourcev1b2.OCIRepository
+	for _, modelName := range bundleFlags.models {
+		model, ok := catalog.Find(catalogModels, modelName)
+		if !ok {
+			return fmt.Errorf("model %s is not in the catalog, see weave-ai list-models --catalog", modelName)
+		}
+		chosen = append(chosen, model)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	logger.Generatef("generating manifests")
+	manifests, err := bundleManifests(ctx, bundleFlags.models)
+	if err != nil {
+		return err
+	}
+	logger.Successf("manifests build completed")
+
+	images, err := bundleImages(manifests)
+	if err != nil {
+		return err
+	}
+
+	// write next to the output and rename, so that an interrupted run
+	// doesn't leave a partial kit behind
+	out, err := os.CreateTemp(filepath.Dir(bundleFlags.output), ".weave-ai-bundle-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	bw := bufio.NewWriter(out)
+	tw := tar.NewWriter(bw)
+	names := make([]string, 0, len(manifests))
+	for file := range manifests {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+	for _, file := range names {
+		if err := writeTarFile(tw, path.Join(bundleManifestsDir, file), manifests[file]); err != nil {
+			return err
+		}
+	}
+	if err := writeTarFile(tw, bundleImagesFile, []byte(strings.Join(images, "\n")+"\n")); err != nil {
+		return err
+	}
+
+	client := &oci.Client{Insecure: bundleFlags.insecure}
+	layout := oci.NewLayoutWriter(tw, bundleLayoutDir)
+	refs := images
+	for _, model := range chosen {
+		refs = append(refs, catalog.Reference(model))
+	}
+	for _, r := range refs {
+		ref, err := client.ParseReference(r)
+		if err != nil {
+			return err
+		}
+		logger.Actionf("adding %s", ref)
+		img, err := client.Fetch(ctx, ref, *platform)
+		if err != nil {
+			return err
+		}
+		err = layout.WriteImage(img, ref.String(), newProgressPrinter())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+	}
+	if err := layout.Close(); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(out.Name(), bundleFlags.output); err != nil {
+		return err
+	}
+
+	logger.Successf("kit written to %s with %d image(s) and %d model(s)", bundleFlags.output, len(images), len(chosen))
+	return nil
+}
+
+// bundleManifests returns the lm-controller manifests of this release and
+// the catalog restricted to the chosen models, by file name.
+func bundleManifests(ctx context.Context, modelNames []string) (map[string][]byte, error) {
+	controllerVersion := defaultLMControllerVersion(Version)
+	files, err := embeddedLMController(controllerVersion)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range lmControllerManifests {
+		if _, ok := files[file]; ok {
+			continue
+		}
+		data, err := fetchManifest(ctx, fmt.Sprintf(lmControllerReleaseURL, controllerVersion, file))
+		if err != nil {
+			return nil, err
+		}
+		files[file] = data
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	if err := writeEmbeddedCatalog(fSys, "/catalog"); err != nil {
+		return nil, err
+	}
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, "/catalog")
+	if err != nil {
+		return nil, err
+	}
+	data, err := m.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+	objs, err := ssa.ReadObjects(strings.NewReader(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	wanted := map[string]bool{}
+	for _, n := range modelNames {
+		wanted[n] = true
+	}
+	var kept []*unstructured.Unstructured
+	for _, obj := range objs {
+		if obj.GetKind() == sourcev1b2.OCIRepositoryKind && wanted[obj.GetName()] {
+			kept = append(kept, obj)
+		}
+	}
+	catalogYAML, err := ssa.ObjectsToYAML(kept)
+	if err != nil {
+		return nil, err
+	}
+	files[modelCatalogManifest] = []byte(catalogYAML)
+	return files, nil
+}
+
+func fetchManifest(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s failed: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+}
+
+// bundleImages lists the images of the controller manifests, the images
+// the lm-controller starts and the chat UI, sorted.
+func bundleImages(manifests map[string][]byte) ([]string, error) {
+	seen := map[string]bool{
+		aiv1a1.ImageEngineLlamaCppPython: true,
+		aiv1a1.ImageBlobDownloader:       true,
+		ImageChatInfo:                    true,
+	}
+	for _, image := range bundleFlags.images {
+		seen[image] = true
+	}
+	for _, file := range lmControllerManifests {
+		objs, err := ssa.ReadObjects(strings.NewReader(string(manifests[file])))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		for _, obj := range objs {
+			forEachContainer(obj, func(container map[string]interface{}) {
+				if image, ok := container["image"].(string); ok && image != "" {
+					seen[image] = true
+				}
+			})
+		}
+	}
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// forEachContainer calls fn with the containers and init containers of the
+// pod template of a workload.
+func forEachContainer(obj *unstructured.Unstructured, fn func(container map[string]interface{})) {
+	for _, field := range []string{"containers", "initContainers"} {
+		containers, found, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", field)
+		if !found {
+			continue
+		}
+		for _, c := range containers {
+			if container, ok := c.(map[string]interface{}); ok {
+				fn(container)
+			}
+		}
+		_ = unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", field)
+	}
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func bundleLoadCmdRun(cmd *cobra.Command, args []string) error {
+	kit := args[0]
+	registry := strings.TrimSuffix(strings.TrimPrefix(bundleLoadFlags.registry, "oci://"), "/")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dir := kit
+	info, err := os.Stat(kit)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		dir, err = os.MkdirTemp("", "weave-ai-bundle-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		logger.Actionf("extracting %s", kit)
+		if err := extractKit(kit, dir); err != nil {
+			return fmt.Errorf("extracting %s failed: %w", kit, err)
+		}
+	}
+
+	images, err := oci.ReadLayout(filepath.Join(dir, bundleLayoutDir))
+	if err != nil {
+		return err
+	}
+
+	client := &oci.Client{Insecure: bundleLoadFlags.insecure}
+	// the references of the kit mapped to the ones in the registry, and the
+	// same for the repositories, which is what OCIRepository URLs hold
+	rewritten := map[string]string{}
+	repositories := map[string]string{}
+	for _, image := range images {
+		src, err := name.ParseReference(image.Ref)
+		if err != nil {
+			return fmt.Errorf("invalid reference %q in the kit: %w", image.Ref, err)
+		}
+		target := registry + "/" + src.Context().RepositoryStr()
+		if digest, ok := src.(name.Digest); ok {
+			target += "@" + digest.DigestStr()
+		} else {
+			target += ":" + src.Identifier()
+		}
+		dst, err := client.ParseReference(target)
+		if err != nil {
+			return err
+		}
+
+		logger.Actionf("pushing %s", dst)
+		err = client.PushImage(ctx, dst, image.Image, newProgressPrinter())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+		rewritten[image.Ref] = dst.String()
+		repositories[src.Context().Name()] = dst.Context().Name()
+	}
+	logger.Successf("pushed %d image(s) and model(s) to %s", len(images), registry)
+
+	files, err := readBundleManifests(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(bundleLoadFlags.outputDir, bundleManifestsDir), 0o755); err != nil {
+		return err
+	}
+	for file, data := range files {
+		objs, err := ssa.ReadObjects(strings.NewReader(string(data)))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+		for _, obj := range objs {
+			if err := rewriteReferences(obj, rewritten, repositories, bundleLoadFlags.insecure); err != nil {
+				return err
+			}
+		}
+		out, err := ssa.ObjectsToYAML(objs)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(bundleLoadFlags.outputDir, bundleManifestsDir, file), []byte(out), 0o644); err != nil {
+			return err
+		}
+	}
+
+	logger.Successf("manifests written to %s", bundleLoadFlags.outputDir)
+	logger.Actionf("install with: weave-ai install --from-bundle %s", bundleLoadFlags.outputDir)
+	return nil
+}
+
+// rewriteReferences points the URL of a model and the images of a workload
+// at the registry the kit was loaded into.
+func rewriteReferences(obj *unstructured.Unstructured, images, repositories map[string]string, insecure bool) error {
+	if obj.GetKind() == sourcev1b2.OCIRepositoryKind {
+		url, _, _ := unstructured.NestedString(obj.Object, "spec", "url")
+		src, err := name.NewRepository(strings.TrimPrefix(url, sourcev1b2.OCIRepositoryPrefix))
+		if err != nil {
+			return fmt.Errorf("invalid URL of model %s: %w", obj.GetName(), err)
+		}
+		dst, ok := repositories[src.Name()]
+		if !ok {
+			return nil
+		}
+		if err := unstructured.SetNestedField(obj.Object, sourcev1b2.OCIRepositoryPrefix+dst, "spec", "url"); err != nil {
+			return err
+		}
+		if insecure {
+			return unstructured.SetNestedField(obj.Object, true, "spec", "insecure")
+		}
+		return nil
+	}
+
+	forEachContainer(obj, func(container map[string]interface{}) {
+		image, _ := container["image"].(string)
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return
+		}
+		if dst, ok := images[ref.String()]; ok {
+			container["image"] = dst
+		}
+	})
+	return nil
+}
+
+// extractKit unpacks a kit, optionally gzipped, into dir.
+func extractKit(kit, dir string) error {
+	f, err := os.Open(kit)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return oci.Extract(br, dir)
+	}
+	return oci.ExtractTar(br, dir)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	aiv1a1 "github.com/weave-ai/lm-controller/api/v1alpha1"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+	"github.com/weave-ai/weave-ai/pkg/chat"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Args:  cobra.ExactArgs(1),
+	Short: "Chat with an LLM from the terminal",
+	Long: `
+# Chat with the LLM my-llm.
+weave-ai chat my-llm
+
+# Chat with my-llm from the dev-space namespace using a system prompt.
+weave-ai chat -n dev-space --system "You are a helpful Kubernetes expert." my-llm
+
+# Chat with an LLM that was started with a smaller context window than its model.
+weave-ai chat --context-size 4096 my-llm
+
+Type /reset to start over, /save [file] to save the conversation as JSON,
+and /exit or Ctrl+D to leave.
+`,
+	RunE: chatCmdRun,
+}
+
+var chatFlags struct {
+	namespace   string
+	system      string
+	contextSize int
+	maxTokens   int
+}
+
+func init() {
+	chatCmd.Flags().StringVar(&chatFlags.system, "system", "", "system prompt sent at the beginning of the conversation")
+	chatCmd.Flags().IntVar(&chatFlags.contextSize, "context-size", 0, "context window of the model in tokens, older messages are dropped to fit in it, defaults to the context length of the model")
+	chatCmd.Flags().IntVar(&chatFlags.maxTokens, "max-tokens", 512, "maximum number of tokens of each answer")
+
+	// TODO use the default namespace from context
+	chatCmd.Flags().StringVarP(&chatFlags.namespace, "namespace", "n", "default", "the namespace of the LLM")
+	rootCmd.AddCommand(chatCmd)
+}
+
+func chatCmdRun(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	contextSize := chatFlags.contextSize
+	if contextSize <= 0 {
+		contextSize = modelContextSize(ctx, chatFlags.namespace, name)
+	}
+	if chatFlags.maxTokens >= contextSize {
+		return fmt.Errorf("--max-tokens must be smaller than the context size %d, set with --context-size", contextSize)
+	}
+
+	logger.Actionf("connecting to %s/%s", chatFlags.namespace, name)
+	tunnel, err := newPortForward(chatFlags.namespace, name, enginePort, 0)
+	if err != nil {
+		return err
+	}
+	go tunnel.Run(ctx)
+
+	readyCtx, cancelFn := context.WithTimeout(ctx, rootArgs.timeout)
+	defer cancelFn()
+	if err := tunnel.WaitReady(readyCtx); err != nil {
+		return fmt.Errorf("could not connect to %s/%s: %w", chatFlags.namespace, name, err)
+	}
+	logger.Successf("connected, type /exit or press Ctrl+D to leave")
+
+	client := &chat.Client{
+		BaseURL:   fmt.Sprintf("http://localhost:%d", tunnel.localPort),
+		Model:     name,
+		MaxTokens: chatFlags.maxTokens,
+	}
+	conversation := &chat.Conversation{
+		System:         chatFlags.system,
+		ContextSize:    contextSize,
+		ReservedTokens: chatFlags.maxTokens,
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Print(">>> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+		input := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case input == "":
+			continue
+		case input == "/exit" || input == "/quit":
+			return nil
+		case input == "/reset":
+			conversation.Reset()
+			logger.Successf("conversation reset")
+			continue
+		case input == "/save" || strings.HasPrefix(input, "/save "):
+			file := strings.TrimSpace(strings.TrimPrefix(input, "/save"))
+			if file == "" {
+				file = fmt.Sprintf("chat-%s-%s.json", name, time.Now().Format("20060102-150405"))
+			}
+			if err := saveConversation(file, conversation); err != nil {
+				logger.Failuref("saving the conversation failed: %v", err)
+			} else {
+				logger.Successf("conversation saved to %s", file)
+			}
+			continue
+		case strings.HasPrefix(input, "/"):
+			logger.Failuref("unknown command %s, use /reset, /save [file] or /exit", input)
+			continue
+		}
+
+		conversation.Add(chat.RoleUser, input)
+		answer, err := client.Stream(ctx, conversation.Prompt(), func(token string) {
+			fmt.Print(token)
+		})
+		fmt.Println()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			// drop the question so that it can be asked again
+			conversation.Messages = conversation.Messages[:len(conversation.Messages)-1]
+			logger.Failuref("%v", err)
+			continue
+		}
+		conversation.Add(chat.RoleAssistant, answer)
+	}
+}
+
+func saveConversation(file string, conversation *chat.Conversation) error {
+	data, err := json.MarshalIndent(conversation.All(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+// defaultContextSize is the context window assumed for models that don't
+// have a context length annotation.
+const defaultContextSize = 2048
+
+// modelContextSize returns the context length annotated on the model of an
+// LLM, or defaultContextSize when it can't be found.
+func modelContextSize(ctx context.Context, namespace, name string) int {
+	ctx, cancelFn := context.WithTimeout(ctx, rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		logger.Warningf("could not read the context length of the model, using %d tokens: %v", defaultContextSize, err)
+		return defaultContextSize
+	}
+	lm := &aiv1a1.LanguageModel{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, lm); err != nil {
+		logger.Warningf("could not read the context length of the model, using %d tokens: %v", defaultContextSize, err)
+		return defaultContextSize
+	}
+	key, ok := modelOf(lm)
+	if !ok {
+		return defaultContextSize
+	}
+	model := &sourcev1b2.OCIRepository{}
+	if err := client.Get(ctx, key, model); err != nil {
+		logger.Warningf("could not read the context length of model %s, using %d tokens: %v", key, defaultContextSize, err)
+		return defaultContextSize
+	}
+	value, ok := model.Annotations[catalog.ContextLengthAnnotation]
+	if !ok {
+		return defaultContextSize
+	}
+	n, err := catalog.ParseContextLength(value)
+	if err != nil || n <= 0 {
+		logger.Warningf("invalid context length %q of model %s, using %d tokens", value, key, defaultContextSize)
+		return defaultContextSize
+	}
+	return int(n)
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	enginePort = 8000
+	uiPort     = 8501
+)
+
+var connectCmd = &cobra.Command{
+	Use:   "connect",
+	Args:  cobra.ExactArgs(1),
+	Short: "Connect to an LLM through port forwarding",
+	Long: `
+# Forward the engine of my-llm to http://localhost:8000.
+weave-ai connect my-llm
+
+# Forward the engine and the chat UI of my-llm.
+weave-ai connect --ui my-llm
+
+# Forward the engine of my-llm from the dev-space namespace to port 9000.
+weave-ai connect -n dev-space --port 9000 my-llm
+`,
+	RunE: connectCmdRun,
+}
+
+var connectFlags struct {
+	namespace string
+	ui        bool
+	port      int
+	uiPort    int
+}
+
+func init() {
+	connectCmd.Flags().BoolVar(&connectFlags.ui, "ui", false, "also forwards the Weave Chat UI")
+	connectCmd.Flags().IntVar(&connectFlags.port, "port", enginePort, "preferred local port for the LLM, a free one is picked when it is busy")
+	connectCmd.Flags().IntVar(&connectFlags.uiPort, "ui-port", uiPort, "preferred local port for the chat UI, a free one is picked when it is busy")
+
+	// TODO use the default namespace from context
+	connectCmd.Flags().StringVarP(&connectFlags.namespace, "namespace", "n", "default", "the namespace of the LLM")
+	rootCmd.AddCommand(connectCmd)
+}
+
+func connectCmdRun(cmd *cobra.Command, args []string) error {
+	return connectLLM(connectFlags.namespace, args[0], connectFlags.ui, connectFlags.port, connectFlags.uiPort)
+}
+
+// connectLLM forwards the engine, and optionally the chat UI, of an LLM to
+// local ports until interrupted.
+func connectLLM(namespace, name string, ui bool, port, uiLocalPort int) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	forwards := []*portForward{}
+	engine, err := newPortForward(namespace, name, enginePort, port)
+	if err != nil {
+		return err
+	}
+	forwards = append(forwards, engine)
+
+	if ui {
+		chat, err := newPortForward(namespace, name+"-chat-app", uiPort, uiLocalPort)
+		if err != nil {
+			return err
+		}
+		forwards = append(forwards, chat)
+	}
+
+	errs := make(chan error, len(forwards))
+	for _, pf := range forwards {
+		pf := pf
+		go func() {
+			errs <- pf.Run(ctx)
+		}()
+	}
+
+	readyCtx, cancelFn := context.WithTimeout(ctx, rootArgs.timeout)
+	defer cancelFn()
+	for _, pf := range forwards {
+		if err := pf.WaitReady(readyCtx); err != nil {
+			return fmt.Errorf("could not connect to %s/%s: %w", namespace, pf.service, err)
+		}
+	}
+
+	logger.Successf("your LLM is available at http://localhost:%d", engine.localPort)
+	if ui {
+		logger.Successf("the chat UI is available at http://localhost:%d", forwards[1].localPort)
+	}
+	logger.Actionf("press Ctrl+C to disconnect")
+
+	for range forwards {
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// portForward forwards a local port to a Service port, following the
+// Service to a new pod whenever the current one goes away.
+type portForward struct {
+	namespace   string
+	service     string
+	servicePort int32
+	localPort   int
+
+	config    *rest.Config
+	clientSet kubernetes.Interface
+
+	readyOnce sync.Once
+	ready     chan struct{}
+}
+
+func newPortForward(namespace, service string, servicePort int32, preferredLocalPort int) (*portForward, error) {
+	config, err := utils.KubeConfig(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return nil, err
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	localPort, err := freeLocalPort(preferredLocalPort)
+	if err != nil {
+		return nil, err
+	}
+	if preferredLocalPort != 0 && localPort != preferredLocalPort {
+		logger.Warningf("port %d is busy, using %d for %s/%s", preferredLocalPort, localPort, namespace, service)
+	}
+
+	return &portForward{
+		namespace:   namespace,
+		service:     service,
+		servicePort: servicePort,
+		localPort:   localPort,
+		config:      config,
+		clientSet:   clientSet,
+		ready:       make(chan struct{}),
+	}, nil
+}
+
+// freeLocalPort returns the preferred port if it can be listened on,
+// otherwise, or when no port is preferred, a port picked by the operating system.
+func freeLocalPort(preferred int) (int, error) {
+	if preferred != 0 {
+		if l, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", preferred)); err == nil {
+			l.Close()
+			return preferred, nil
+		}
+	}
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, fmt.Errorf("could not find a free local port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// WaitReady blocks until the first connection to a pod is established.
+func (pf *portForward) WaitReady(ctx context.Context) error {
+	select {
+	case <-pf.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run forwards the local port until ctx is done, reconnecting when
+// the pod behind the Service is restarted or replaced.
+func (pf *portForward) Run(ctx context.Context) error {
+	waiting := false
+	for {
+		pod, podPort, err := pf.resolvePod(ctx)
+		if err == nil {
+			waiting = false
+			err = pf.forward(ctx, pod, podPort)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && !waiting {
+			logger.Waitingf("waiting for %s/%s to accept connections: %v", pf.namespace, pf.service, err)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(rootArgs.pollInterval):
+		}
+	}
+}
+
+func (pf *portForward) forward(ctx context.Context, pod string, podPort int32) error {
+	transport, upgrader, err := spdy.RoundTripperFor(pf.config)
+	if err != nil {
+		return err
+	}
+	url := pf.clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pf.namespace).
+		Name(pod).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	var stopOnce sync.Once
+	stopFn := func() { stopOnce.Do(func() { close(stopChan) }) }
+	defer stopFn()
+
+	fw, err := portforward.NewOnAddresses(dialer,
+		[]string{"localhost"},
+		[]string{fmt.Sprintf("%d:%d", pf.localPort, podPort)},
+		stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		select {
+		case <-readyChan:
+			pf.readyOnce.Do(func() { close(pf.ready) })
+		case <-stopChan:
+		}
+	}()
+
+	// the stream is not always closed when a pod is deleted, so watch it
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				stopFn()
+				return
+			case <-stopChan:
+				return
+			case <-time.After(rootArgs.pollInterval):
+			}
+			p, err := pf.clientSet.CoreV1().Pods(pf.namespace).Get(ctx, pod, metav1.GetOptions{})
+			if err != nil || p.DeletionTimestamp != nil || p.Status.Phase != corev1.PodRunning {
+				stopFn()
+				return
+			}
+		}
+	}()
+
+	if err := fw.ForwardPorts(); err != nil {
+		return err
+	}
+	if ctx.Err() == nil {
+		return fmt.Errorf("pod %s went away", pod)
+	}
+	return nil
+}
+
+// resolvePod picks a running pod behind the Service and translates the
+// Service port into the container port, the way kubectl port-forward does.
+func (pf *portForward) resolvePod(ctx context.Context) (string, int32, error) {
+	svc, err := pf.clientSet.CoreV1().Services(pf.namespace).Get(ctx, pf.service, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+
+	var svcPort *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == pf.servicePort {
+			svcPort = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if svcPort == nil {
+		return "", 0, fmt.Errorf("service %s/%s has no port %d", pf.namespace, pf.service, pf.servicePort)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s/%s has no selector", pf.namespace, pf.service)
+	}
+
+	pods, err := pf.clientSet.CoreV1().Pods(pf.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	var pod *corev1.Pod
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.DeletionTimestamp != nil || p.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		pod = p
+		if isPodReady(p) {
+			break
+		}
+	}
+	if pod == nil {
+		return "", 0, fmt.Errorf("no running pods found for service %s/%s", pf.namespace, pf.service)
+	}
+
+	switch {
+	case svcPort.TargetPort.IntVal != 0:
+		return pod.Name, svcPort.TargetPort.IntVal, nil
+	case svcPort.TargetPort.StrVal != "":
+		for _, c := range pod.Spec.Containers {
+			for _, p := range c.Ports {
+				if p.Name == svcPort.TargetPort.StrVal {
+					return pod.Name, p.ContainerPort, nil
+				}
+			}
+		}
+		return "", 0, fmt.Errorf("pod %s/%s has no port named %s", pf.namespace, pod.Name, svcPort.TargetPort.StrVal)
+	}
+	return pod.Name, svcPort.Port, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/fluxcd/pkg/ssa"
 	"github.com/spf13/cobra"
 	"github.com/weave-ai/weave-ai/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var createLmCmd = &cobra.Command{
@@ -24,7 +25,7 @@ var createLmFlags struct {
 	model          string
 	modelNamespace string
 	serviceType    string
-	cpu            string
+	resources      engineResourceFlags
 	export         bool
 	wait           bool
 }
@@ -33,7 +34,7 @@ func init() {
 	createLmCmd.Flags().StringVarP(&createLmFlags.model, "model", "m", "zephyr-7b-beta", "model name")
 	createLmCmd.Flags().StringVarP(&createLmFlags.modelNamespace, "model-ns", "N", defaultNamespace, "model namespace")
 	createLmCmd.Flags().StringVarP(&createLmFlags.serviceType, "service-type", "s", "ClusterIP", "service type: ClusterIP, NodePort, LoadBalancer, ExternalName")
-	createLmCmd.Flags().StringVarP(&createLmFlags.cpu, "cpu", "c", "4", "cpu")
+	createLmFlags.resources.addFlags(createLmCmd.Flags())
 	createLmCmd.Flags().BoolVar(&createLmFlags.export, "export", false, "export manifests instead of installing")
 	createLmCmd.Flags().BoolVar(&createLmFlags.wait, "wait", false, "wait for the resources to be reconciled")
 
@@ -42,6 +43,11 @@ func init() {
 
 func createLmCmdRun(cmd *cobra.Command, args []string) error {
 	lmName := args[0]
+	resources, err := createLmFlags.resources.requirements()
+	if err != nil {
+		return err
+	}
+
 	lmtemplate := `---
 apiVersion: ai.contrib.fluxcd.io/v1alpha1
 kind: LanguageModel
@@ -59,9 +65,21 @@ spec:
   engine:
     serviceType: {{ .ServiceType }}
     replicas: 1
+{{- if or .Requests .Limits }}
     resources:
+{{- if .Requests }}
       requests:
-        cpu: "{{ .CPU }}"
+{{- range $name, $quantity := .Requests }}
+        {{ $name }}: "{{ $quantity }}"
+{{- end }}
+{{- end }}
+{{- if .Limits }}
+      limits:
+{{- range $name, $quantity := .Limits }}
+        {{ $name }}: "{{ $quantity }}"
+{{- end }}
+{{- end }}
+{{- end }}
 `
 	tpl, err := template.New("create-lm").Parse(lmtemplate)
 	if err != nil {
@@ -73,14 +91,16 @@ spec:
 		Model          string
 		ModelNamespace string
 		ServiceType    string
-		CPU            string
+		Requests       map[string]string
+		Limits         map[string]string
 	}{
 		LMName:         lmName,
 		LMNamespace:    *kubeconfigArgs.Namespace,
 		Model:          createLmFlags.model,
 		ModelNamespace: createLmFlags.modelNamespace,
 		ServiceType:    createLmFlags.serviceType,
-		CPU:            createLmFlags.cpu,
+		Requests:       quantities(resources.Requests),
+		Limits:         quantities(resources.Limits),
 	}
 
 	var buffer bytes.Buffer
@@ -106,3 +126,16 @@ spec:
 
 	return nil
 }
+
+// quantities returns the resources as strings for the template, which
+// ranges over them in the order of their names.
+func quantities(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	out := map[string]string{}
+	for name, quantity := range list {
+		out[string(name)] = quantity.String()
+	}
+	return out
+}
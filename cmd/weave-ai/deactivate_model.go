@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	aiv1a1 "github.com/weave-ai/lm-controller/api/v1alpha1"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var deactivateModelCmd = &cobra.Command{
+	Use:   "deactivate-model",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Deactivate a model",
+	Long: `
+# Deactivate the zephyr-7b-beta model from the weave-ai namespace
+weave-ai deactivate-model zephyr-7b-beta
+
+# Deactivate two models from the weave-ai namespace
+weave-ai deactivate-model weave-ai/zephyr-7b-beta weave-ai/tinyllama-1.1b-chat
+
+# Deactivate the zephyr-7b-beta model even if LLMs still use it
+weave-ai deactivate-model --force zephyr-7b-beta
+`,
+	RunE: deactivateModelCmdRun,
+}
+
+var deactivateModelFlags struct {
+	force bool
+}
+
+func init() {
+	deactivateModelCmd.Flags().BoolVarP(&deactivateModelFlags.force, "force", "f", false, "Deactivate the model even if LLMs still use it")
+	rootCmd.AddCommand(deactivateModelCmd)
+}
+
+func deactivateModelCmdRun(cmd *cobra.Command, args []string) error {
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, modelName := range args {
+		key := types.NamespacedName{Namespace: defaultNamespace, Name: modelName}
+		// if model name contains / split it into model namespace and model name
+		if strings.Contains(modelName, "/") {
+			split := strings.SplitN(modelName, "/", 2)
+			key = types.NamespacedName{Namespace: split[0], Name: split[1]}
+		}
+
+		size, err := deactivateModel(ctx, client, key, deactivateModelFlags.force)
+		if err != nil {
+			return err
+		}
+		total += size
+	}
+
+	if len(args) > 1 {
+		logger.Successf("%s of artifacts no longer reconciled in total", humanize.Bytes(uint64(total)))
+	}
+
+	return nil
+}
+
+// deactivateModel suspends a model and returns the size of its artifact.
+// The source-controller stops reconciling it but keeps the artifact in its
+// storage until the OCIRepository is deleted.
+func deactivateModel(ctx context.Context, client runtimeclient.Client, key types.NamespacedName, force bool) (int64, error) {
+	logger.Actionf("checking if model %s is used by any LLM", key)
+	users, err := modelUsers(ctx, client, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(users) > 0 {
+		if !force {
+			return 0, fmt.Errorf("model %s is still used by %s, remove them first or use --force", key, strings.Join(users, ", "))
+		}
+		logger.Warningf("model %s is still used by %s", key, strings.Join(users, ", "))
+	}
+
+	model := &sourcev1b2.OCIRepository{}
+	if err := client.Get(ctx, key, model); err != nil {
+		return 0, err
+	}
+	if model.Spec.Suspend {
+		logger.Successf("model %s is already inactive", key)
+		return 0, nil
+	}
+
+	if err := suspendModel(ctx, client, model); err != nil {
+		return 0, err
+	}
+
+	var size int64
+	if model.Status.Artifact != nil && model.Status.Artifact.Size != nil {
+		size = *model.Status.Artifact.Size
+		logger.Successf("model %s is no longer reconciled, artifact size %s", key, humanize.Bytes(uint64(size)))
+	}
+	return size, nil
+}
+
+// modelUsers returns the LanguageModels whose source is the given model.
+func modelUsers(ctx context.Context, client runtimeclient.Client, model types.NamespacedName) ([]string, error) {
+	list := &aiv1a1.LanguageModelList{}
+	if err := client.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	var users []string
+	for _, lm := range list.Items {
+		ref := lm.Spec.SourceRef
+		if ref.Kind != "" && ref.Kind != sourcev1b2.OCIRepositoryKind {
+			continue
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = lm.Namespace
+		}
+		if ref.Name == model.Name && namespace == model.Namespace {
+			users = append(users, lm.Namespace+"/"+lm.Name)
+		}
+	}
+	return users, nil
+}
+
+func suspendModel(ctx context.Context, client runtimeclient.Client, model *sourcev1b2.OCIRepository) error {
+	logger.Actionf("deactivate model %s/%s", model.Namespace, model.Name)
+	patch := runtimeclient.MergeFrom(model.DeepCopy())
+	model.Spec.Suspend = true
+	if err := client.Patch(ctx, model, patch); err != nil {
+		return err
+	}
+	logger.Successf("model %s/%s deactivated", model.Namespace, model.Name)
+	return nil
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	aiv1a1 "github.com/weave-ai/lm-controller/api/v1alpha1"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Show the details of a model or an LLM",
+	Long: `
+# Show why the zephyr-7b-beta model is not active
+weave-ai describe model weave-ai/zephyr-7b-beta
+
+# Show why the my-llm LLM is not ready
+weave-ai describe lm my-llm
+`,
+}
+
+var describeModelCmd = &cobra.Command{
+	Use:   "model [namespace/name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Show the conditions, artifact, users and events of a model",
+	RunE:  describeModelCmdRun,
+}
+
+var describeLMCmd = &cobra.Command{
+	Use:     "lm [name]",
+	Aliases: []string{"llm"},
+	Args:    cobra.ExactArgs(1),
+	Short:   "Show the conditions, objects, pods and events of an LLM",
+	RunE:    describeLMCmdRun,
+}
+
+var describeFlags struct {
+	namespace string
+}
+
+func init() {
+	// TODO use the default namespace from context
+	describeLMCmd.Flags().StringVarP(&describeFlags.namespace, "namespace", "n", "default", "namespace of the LLM")
+
+	describeCmd.AddCommand(describeModelCmd)
+	describeCmd.AddCommand(describeLMCmd)
+	rootCmd.AddCommand(describeCmd)
+}
+
+func describeModelCmdRun(cmd *cobra.Command, args []string) error {
+	key := types.NamespacedName{Namespace: defaultNamespace, Name: args[0]}
+	if ns, name, ok := strings.Cut(args[0], "/"); ok {
+		key = types.NamespacedName{Namespace: ns, Name: name}
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	model := &sourcev1b2.OCIRepository{}
+	if err := client.Get(ctx, key, model); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("model %s not found", key)
+		}
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", model.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", model.Namespace)
+	fmt.Fprintf(w, "Status:\t%s\n", getStatus(*model))
+	fmt.Fprintf(w, "Suspended:\t%t\n", model.Spec.Suspend)
+	fmt.Fprintf(w, "URL:\t%s\n", model.Spec.URL)
+	if ref := model.Spec.Reference; ref != nil {
+		switch {
+		case ref.Digest != "":
+			fmt.Fprintf(w, "Digest:\t%s\n", ref.Digest)
+		case ref.SemVer != "":
+			fmt.Fprintf(w, "SemVer:\t%s\n", ref.SemVer)
+		case ref.Tag != "":
+			fmt.Fprintf(w, "Tag:\t%s\n", ref.Tag)
+		}
+	}
+	fmt.Fprintf(w, "Created:\t%s\n", describeTime(model.CreationTimestamp.Time))
+
+	if artifact := model.Status.Artifact; artifact != nil {
+		fmt.Fprintf(w, "Artifact:\t\n")
+		fmt.Fprintf(w, "  URL:\t%s\n", artifact.URL)
+		fmt.Fprintf(w, "  Revision:\t%s\n", artifact.Revision)
+		fmt.Fprintf(w, "  Digest:\t%s\n", artifact.Digest)
+		if artifact.Size != nil {
+			fmt.Fprintf(w, "  Size:\t%s\n", humanize.Bytes(uint64(*artifact.Size)))
+		}
+		fmt.Fprintf(w, "  Last Update:\t%s\n", describeTime(artifact.LastUpdateTime.Time))
+		if len(artifact.Metadata) > 0 {
+			fmt.Fprintf(w, "  Metadata:\t\n")
+			keys := make([]string, 0, len(artifact.Metadata))
+			for k := range artifact.Metadata {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(w, "    %s:\t%s\n", k, artifact.Metadata[k])
+			}
+		}
+	} else {
+		fmt.Fprintf(w, "Artifact:\t<none>\n")
+	}
+	w.Flush()
+
+	describeConditions(os.Stdout, model.Status.Conditions)
+
+	users, err := modelUsers(ctx, client, key)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Used By:")
+	if len(users) == 0 {
+		fmt.Println("  <none>")
+	}
+	for _, user := range users {
+		fmt.Printf("  %s\n", user)
+	}
+
+	events, err := eventsFor(ctx, client, model.Namespace, map[string]bool{sourcev1b2.OCIRepositoryKind + "/" + model.Name: true})
+	if err != nil {
+		return err
+	}
+	describeEvents(os.Stdout, events)
+	return nil
+}
+
+func describeLMCmdRun(cmd *cobra.Command, args []string) error {
+	key := types.NamespacedName{Namespace: describeFlags.namespace, Name: args[0]}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	lm := &aiv1a1.LanguageModel{}
+	if err := client.Get(ctx, key, lm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("LLM %s not found", key)
+		}
+		return err
+	}
+
+	ready, reason := getReadiness(*lm)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", lm.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", lm.Namespace)
+	fmt.Fprintf(w, "Model:\t%s\n", getModel(*lm))
+	fmt.Fprintf(w, "Ready:\t%s\n", ready)
+	if reason != "" {
+		fmt.Fprintf(w, "Reason:\t%s\n", reason)
+	}
+	fmt.Fprintf(w, "Suspended:\t%t\n", lm.Spec.Suspend)
+	fmt.Fprintf(w, "Service Type:\t%s\n", getServiceType(*lm))
+	fmt.Fprintf(w, "URL:\t%s\n", engineURL(lm.Namespace, lm.Name))
+	if lm.Spec.Engine.Replicas != nil {
+		fmt.Fprintf(w, "Replicas:\t%d\n", *lm.Spec.Engine.Replicas)
+	}
+	describeResources(w, "Requests", lm.Spec.Engine.Resources.Requests)
+	describeResources(w, "Limits", lm.Spec.Engine.Resources.Limits)
+	fmt.Fprintf(w, "Last Applied Revision:\t%s\n", lm.Status.LastAppliedRevision)
+	fmt.Fprintf(w, "Last Attempted Revision:\t%s\n", lm.Status.LastAttemptedRevision)
+	fmt.Fprintf(w, "Created:\t%s\n", describeTime(lm.CreationTimestamp.Time))
+	w.Flush()
+
+	describeConditions(os.Stdout, lm.Status.Conditions)
+
+	involved := map[string]bool{aiv1a1.LanguageModelKind + "/" + lm.Name: true}
+
+	objs, err := ownedObjects(lm)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Objects:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  KIND\tNAME\tSTATUS\n")
+	for _, obj := range objs {
+		status := "Present"
+		if err := client.Get(ctx, runtimeclient.ObjectKeyFromObject(obj), obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			// the chat app is only there when the LLM was run with --ui
+			if strings.HasSuffix(obj.GetName(), "-chat-app") {
+				continue
+			}
+			status = "Missing"
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if kind == "" {
+			kind = kindOf(obj)
+		}
+		involved[kind+"/"+obj.GetName()] = true
+		fmt.Fprintf(w, "  %s\t%s/%s\t%s\n", kind, obj.GetNamespace(), obj.GetName(), status)
+	}
+	w.Flush()
+
+	pods := &corev1.PodList{}
+	if err := client.List(ctx, pods, runtimeclient.InNamespace(lm.Namespace)); err != nil {
+		return err
+	}
+	fmt.Println("Pods:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  NAME\tREADY\tSTATUS\tRESTARTS\tNODE\tAGE\n")
+	for _, pod := range pods.Items {
+		app := pod.Labels["app"]
+		if app != lm.Name && app != lm.Name+"-chat-app" {
+			continue
+		}
+		involved["Pod/"+pod.Name] = true
+		var readyContainers, restarts int32
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Ready {
+				readyContainers++
+			}
+			restarts += cs.RestartCount
+		}
+		fmt.Fprintf(w, "  %s\t%d/%d\t%s\t%d\t%s\t%s\n",
+			pod.Name,
+			readyContainers, len(pod.Spec.Containers),
+			podStatus(&pod),
+			restarts,
+			pod.Spec.NodeName,
+			humanize.Time(pod.CreationTimestamp.Time),
+		)
+	}
+	w.Flush()
+
+	events, err := eventsFor(ctx, client, lm.Namespace, involved)
+	if err != nil {
+		return err
+	}
+	describeEvents(os.Stdout, events)
+	return nil
+}
+
+func kindOf(obj runtimeclient.Object) string {
+	t := fmt.Sprintf("%T", obj)
+	return t[strings.LastIndex(t, ".")+1:]
+}
+
+func describeResources(w io.Writer, title string, resources corev1.ResourceList) {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		q := resources[corev1.ResourceName(name)]
+		fmt.Fprintf(w, "%s %s:\t%s\n", title, name, q.String())
+	}
+}
+
+// podStatus returns the reason a pod is not running, such as
+// ImagePullBackOff, or else its phase.
+func podStatus(pod *corev1.Pod) string {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return cs.State.Waiting.Reason
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
+			return cs.State.Terminated.Reason
+		}
+	}
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return string(pod.Status.Phase)
+}
+
+func describeConditions(out io.Writer, conditions []metav1.Condition) {
+	fmt.Fprintln(out, "Conditions:")
+	if len(conditions) == 0 {
+		fmt.Fprintln(out, "  <none>")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  TYPE\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE\n")
+	for _, c := range conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, describeTime(c.LastTransitionTime.Time), c.Message)
+	}
+	w.Flush()
+}
+
+// eventsFor returns the events of a namespace about the objects given as
+// Kind/name, oldest first.
+func eventsFor(ctx context.Context, client runtimeclient.Client, namespace string, involved map[string]bool) ([]corev1.Event, error) {
+	list := &corev1.EventList{}
+	if err := client.List(ctx, list, runtimeclient.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var events []corev1.Event
+	for _, ev := range list.Items {
+		if involved[ev.InvolvedObject.Kind+"/"+ev.InvolvedObject.Name] {
+			events = append(events, ev)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	return events, nil
+}
+
+func eventTime(ev corev1.Event) time.Time {
+	switch {
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	case !ev.FirstTimestamp.IsZero():
+		return ev.FirstTimestamp.Time
+	}
+	return ev.CreationTimestamp.Time
+}
+
+func describeEvents(out io.Writer, events []corev1.Event) {
+	fmt.Fprintln(out, "Events:")
+	if len(events) == 0 {
+		fmt.Fprintln(out, "  <none>")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE\n")
+	for _, ev := range events {
+		object := ev.InvolvedObject.Kind + "/" + ev.InvolvedObject.Name
+		message := strings.TrimSpace(ev.Message)
+		if ev.Count > 1 {
+			message = fmt.Sprintf("%s (x%d)", message, ev.Count)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", humanize.Time(eventTime(ev)), ev.Type, ev.Reason, object, message)
+	}
+	w.Flush()
+}
+
+func describeTime(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return t.Format(time.RFC3339) + " (" + humanize.Time(t) + ")"
+}
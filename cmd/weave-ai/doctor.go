@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/version"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Args:  cobra.NoArgs,
+	Short: "Check that the cluster can run Weave AI",
+	Long: `Check the prerequisites of Weave AI: the Kubernetes and Flux versions, the
+NetworkPolicies of Flux, the resources of the nodes, the default StorageClass,
+the health of the lm-controller and the permissions of the current user.
+Each check passes, warns or fails, and the command fails when a check does.
+
+# Check the cluster
+weave-ai doctor
+
+# Check the cluster from a script
+weave-ai doctor -o json
+`,
+	RunE: doctorCmdRun,
+}
+
+var doctorFlags struct {
+	fluxNamespace   string
+	tenantNamespace string
+	output          string
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorFlags.fluxNamespace, "flux-namespace", "flux-system", "namespace Flux is installed in")
+	doctorCmd.Flags().StringVar(&doctorFlags.tenantNamespace, "tenant-namespace", "default", "namespace the current user runs LLMs in")
+	doctorCmd.Flags().StringVarP(&doctorFlags.output, "output", "o", "", "output format, "+outputFlagUsage)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// minimums documented in the README
+var (
+	minKubernetesVersion       = version.MustParseGeneric("v1.27.0")
+	minFluxVersion             = version.MustParseGeneric("v2.1.0")
+	minSourceControllerVersion = version.MustParseGeneric("v1.1.0") // shipped with Flux v2.1.0
+	minNodeCPU                 = resource.MustParse("8")
+	minNodeMemory              = resource.MustParse("16G")
+	minNodeStorage             = resource.MustParse("100G")
+)
+
+const sourceControllerName = "source-controller"
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "pass"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+)
+
+type checkItem struct {
+	Name    string      `json:"name"`
+	Status  checkStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+var checkColumns = []column[checkItem]{
+	{header: "CHECK", value: func(c checkItem) string { return c.Name }},
+	{header: "STATUS", value: func(c checkItem) string { return string(c.Status) }},
+	{header: "MESSAGE", value: func(c checkItem) string { return c.Message }},
+}
+
+func doctorCmdRun(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(doctorFlags.output); err != nil {
+		return err
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	checks := []func(context.Context, runtimeclient.Client) checkItem{
+		checkKubernetesVersion,
+		checkSourceController,
+		checkFluxCRDs,
+		checkFluxNetworkPolicies,
+		checkNodeResources,
+		checkDefaultStorageClass,
+		checkLMController,
+		checkPermissions,
+	}
+	var items []checkItem
+	failed := 0
+	for _, check := range checks {
+		item := check(ctx, client)
+		if item.Status == checkFail {
+			failed++
+		}
+		items = append(items, item)
+	}
+
+	if err := printList(os.Stdout, doctorFlags.output, items, checkColumns, func(item checkItem) string {
+		return item.Name
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(items))
+	}
+	return nil
+}
+
+func checkKubernetesVersion(_ context.Context, _ runtimeclient.Client) checkItem {
+	item := checkItem{Name: "kubernetes"}
+	dc, err := kubeconfigArgs.ToDiscoveryClient()
+	if err != nil {
+		return item.fail("%v", err)
+	}
+	info, err := dc.ServerVersion()
+	if err != nil {
+		return item.fail("getting the server version failed: %v", err)
+	}
+	v, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return item.warn("unknown server version %s", info.GitVersion)
+	}
+	if !v.AtLeast(minKubernetesVersion) {
+		return item.fail("server version %s is older than v%s", info.GitVersion, minKubernetesVersion)
+	}
+	return item.pass("server version %s", info.GitVersion)
+}
+
+func checkSourceController(ctx context.Context, client runtimeclient.Client) checkItem {
+	item := checkItem{Name: "flux-source-controller"}
+	deploy := &appsv1.Deployment{}
+	key := runtimeclient.ObjectKey{Namespace: doctorFlags.fluxNamespace, Name: sourceControllerName}
+	if err := client.Get(ctx, key, deploy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return item.fail("deployment %s/%s not found, install Flux with: flux install --network-policy=false", key.Namespace, key.Name)
+		}
+		return item.fail("%v", err)
+	}
+	if msg, ok := deploymentReady(deploy); !ok {
+		return item.fail("deployment %s/%s is not ready: %s", key.Namespace, key.Name, msg)
+	}
+
+	tag := imageTag(deploy, sourceControllerName)
+	v, err := version.ParseGeneric(tag)
+	if err != nil {
+		return item.warn("unknown version %q, v%s or later is required", tag, minSourceControllerVersion)
+	}
+	if !v.AtLeast(minSourceControllerVersion) {
+		return item.fail("version %s is older than v%s, upgrade Flux to v%s or later", tag, minSourceControllerVersion, minFluxVersion)
+	}
+	return item.pass("version %s is ready", tag)
+}
+
+func checkFluxCRDs(ctx context.Context, client runtimeclient.Client) checkItem {
+	item := checkItem{Name: "flux-crds"}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	key := runtimeclient.ObjectKey{Name: "ocirepositories.source.toolkit.fluxcd.io"}
+	if err := client.Get(ctx, key, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return item.fail("CRD %s not found, install Flux v%s or later", key.Name, minFluxVersion)
+		}
+		return item.fail("%v", err)
+	}
+
+	served := false
+	for _, v := range crd.Spec.Versions {
+		if v.Name == "v1beta2" && v.Served {
+			served = true
+		}
+	}
+	if !served {
+		return item.fail("CRD %s doesn't serve v1beta2, upgrade Flux to v%s or later", key.Name, minFluxVersion)
+	}
+
+	fluxVersion := crd.Labels["app.kubernetes.io/version"]
+	v, err := version.ParseGeneric(fluxVersion)
+	if err != nil {
+		return item.warn("CRDs of an unknown Flux version %q", fluxVersion)
+	}
+	if !v.AtLeast(minFluxVersion) {
+		return item.fail("CRDs of Flux %s are older than v%s", fluxVersion, minFluxVersion)
+	}
+	return item.pass("CRDs of Flux %s", fluxVersion)
+}
+
+// checkFluxNetworkPolicies looks for policies denying the engine pods, in
+// the namespaces of the tenants, the download of models from the
+// source-controller.
+func checkFluxNetworkPolicies(ctx context.Context, client runtimeclient.Client) checkItem {
+	item := checkItem{Name: "flux-network-policies"}
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := client.List(ctx, policies, runtimeclient.InNamespace(doctorFlags.fluxNamespace)); err != nil {
+		return item.fail("%v", err)
+	}
+
+	podLabels := labels.Set{"app": sourceControllerName}
+	deploy := &appsv1.Deployment{}
+	if err := client.Get(ctx, runtimeclient.ObjectKey{Namespace: doctorFlags.fluxNamespace, Name: sourceControllerName}, deploy); err == nil {
+		podLabels = deploy.Spec.Template.Labels
+	}
+
+	var blocking []string
+	for _, policy := range policies.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil || !selector.Matches(podLabels) {
+			continue
+		}
+		if restrictsIngress(policy) {
+			blocking = append(blocking, policy.Name)
+		}
+	}
+	if len(blocking) > 0 {
+		return item.fail("NetworkPolicies %s in %s block the engines from downloading models, reinstall Flux with: flux install --network-policy=false",
+			strings.Join(blocking, ", "), doctorFlags.fluxNamespace)
+	}
+	return item.pass("no NetworkPolicy blocks the source-controller")
+}
+
+// restrictsIngress tells whether a policy lets only some namespaces reach
+// the pods it selects.
+func restrictsIngress(policy networkingv1.NetworkPolicy) bool {
+	ingress := len(policy.Spec.PolicyTypes) == 0
+	for _, t := range policy.Spec.PolicyTypes {
+		if t == networkingv1.PolicyTypeIngress {
+			ingress = true
+		}
+	}
+	if !ingress {
+		return false
+	}
+	for _, rule := range policy.Spec.Ingress {
+		if len(rule.From) == 0 {
+			return false
+		}
+		for _, peer := range rule.From {
+			if peer.IPBlock == nil && isEmptySelector(peer.NamespaceSelector) && (peer.PodSelector == nil || isEmptySelector(peer.PodSelector)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isEmptySelector(s *metav1.LabelSelector) bool {
+	return s != nil && len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0
+}
+
+func checkNodeResources(ctx context.Context, client runtimeclient.Client) checkItem {
+	item := checkItem{Name: "node-resources"}
+	nodes := &corev1.NodeList{}
+	if err := client.List(ctx, nodes); err != nil {
+		return item.fail("%v", err)
+	}
+
+	cpu, memory, storage := resource.Quantity{}, resource.Quantity{}, resource.Quantity{}
+	schedulable := 0
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		schedulable++
+		cpu.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		memory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+		storage.Add(node.Status.Allocatable[corev1.ResourceEphemeralStorage])
+	}
+	if schedulable == 0 {
+		return item.fail("no schedulable node")
+	}
+
+	allocatable := fmt.Sprintf("%d node(s) with %s CPUs, %s of memory and %s of storage allocatable",
+		schedulable, cpu.String(), memory.String(), storage.String())
+	var low []string
+	if cpu.Cmp(minNodeCPU) < 0 {
+		low = append(low, minNodeCPU.String()+" CPUs")
+	}
+	if memory.Cmp(minNodeMemory) < 0 {
+		low = append(low, minNodeMemory.String()+" of memory")
+	}
+	if storage.Cmp(minNodeStorage) < 0 {
+		low = append(low, minNodeStorage.String()+" of storage")
+	}
+	if len(low) > 0 {
+		return item.warn("%s, at least %s are recommended", allocatable, strings.Join(low, ", "))
+	}
+	return item.pass("%s", allocatable)
+}
+
+func checkDefaultStorageClass(ctx context.Context, client runtimeclient.Client) checkItem {
+	item := checkItem{Name: "default-storage-class"}
+	classes := &storagev1.StorageClassList{}
+	if err := client.List(ctx, classes); err != nil {
+		return item.fail("%v", err)
+	}
+
+	var defaults []string
+	for _, sc := range classes.Items {
+		if sc.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" ||
+			sc.Annotations["storageclass.beta.kubernetes.io/is-default-class"] == "true" {
+			defaults = append(defaults, sc.Name)
+		}
+	}
+	switch len(defaults) {
+	case 0:
+		return item.warn("no default StorageClass, volumes claimed without a storage class stay pending")
+	case 1:
+		return item.pass("%s", defaults[0])
+	default:
+		return item.warn("several default StorageClasses: %s", strings.Join(defaults, ", "))
+	}
+}
+
+func checkLMController(ctx context.Context, client runtimeclient.Client) checkItem {
+	item := checkItem{Name: "lm-controller"}
+	deploy := &appsv1.Deployment{}
+	key := runtimeclient.ObjectKey{Namespace: *kubeconfigArgs.Namespace, Name: "lm-controller"}
+	if err := client.Get(ctx, key, deploy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return item.warn("deployment %s/%s not found, install it with: weave-ai install", key.Namespace, key.Name)
+		}
+		return item.fail("%v", err)
+	}
+	if msg, ok := deploymentReady(deploy); !ok {
+		return item.fail("deployment %s/%s is not ready: %s", key.Namespace, key.Name, msg)
+	}
+	return item.pass("version %s is ready", imageTag(deploy, "lm-controller"))
+}
+
+// accessCheck is a permission of the current user. The install ones are
+// only needed to install and upgrade Weave AI.
+type accessCheck struct {
+	attributes authorizationv1.ResourceAttributes
+	install    bool
+}
+
+func checkPermissions(ctx context.Context, client runtimeclient.Client) checkItem {
+	item := checkItem{Name: "permissions"}
+	tenant, installNs := doctorFlags.tenantNamespace, *kubeconfigArgs.Namespace
+	checks := []accessCheck{
+		{attributes: authorizationv1.ResourceAttributes{Verb: "create", Group: "ai.contrib.fluxcd.io", Resource: "languagemodels", Namespace: tenant}},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "delete", Group: "ai.contrib.fluxcd.io", Resource: "languagemodels", Namespace: tenant}},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "list", Group: "source.toolkit.fluxcd.io", Resource: "ocirepositories", Namespace: installNs}},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "patch", Group: "source.toolkit.fluxcd.io", Resource: "ocirepositories", Namespace: installNs}},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}, install: true},
+		{attributes: authorizationv1.ResourceAttributes{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}, install: true},
+	}
+
+	var denied []string
+	status := checkPass
+	for _, check := range checks {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &check.attributes},
+		}
+		if err := client.Create(ctx, review); err != nil {
+			return item.fail("reviewing the access of the current user failed: %v", err)
+		}
+		if review.Status.Allowed {
+			continue
+		}
+		denied = append(denied, describeAccess(check.attributes))
+		if !check.install {
+			status = checkFail
+		} else if status == checkPass {
+			status = checkWarn
+		}
+	}
+
+	switch status {
+	case checkFail:
+		return item.fail("the current user can't %s", strings.Join(denied, ", "))
+	case checkWarn:
+		return item.warn("the current user can't %s, which install needs", strings.Join(denied, ", "))
+	}
+	return item.pass("the current user can install Weave AI and run LLMs in %s", tenant)
+}
+
+func describeAccess(a authorizationv1.ResourceAttributes) string {
+	resource := a.Resource
+	if a.Group != "" {
+		resource += "." + a.Group
+	}
+	if a.Namespace != "" {
+		return fmt.Sprintf("%s %s in %s", a.Verb, resource, a.Namespace)
+	}
+	return fmt.Sprintf("%s %s", a.Verb, resource)
+}
+
+// deploymentReady tells whether all the replicas of a deployment are
+// updated and available, or why not.
+func deploymentReady(deploy *appsv1.Deployment) (string, bool) {
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	if deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.UpdatedReplicas == replicas &&
+		deploy.Status.AvailableReplicas == replicas {
+		return "", true
+	}
+	for _, c := range deploy.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable && c.Status != corev1.ConditionTrue {
+			return c.Message, false
+		}
+	}
+	return fmt.Sprintf("%d/%d replicas available", deploy.Status.AvailableReplicas, replicas), false
+}
+
+// imageTag returns the tag of the image of the container named like the
+// controller, or of the first container.
+func imageTag(deploy *appsv1.Deployment, name string) string {
+	containers := deploy.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return ""
+	}
+	image := containers[0].Image
+	for _, c := range containers {
+		if strings.Contains(c.Image, name) {
+			image = c.Image
+			break
+		}
+	}
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+func (c checkItem) pass(format string, a ...interface{}) checkItem {
+	c.Status, c.Message = checkPass, fmt.Sprintf(format, a...)
+	return c
+}
+
+func (c checkItem) warn(format string, a ...interface{}) checkItem {
+	c.Status, c.Message = checkWarn, fmt.Sprintf(format, a...)
+	return c
+}
+
+func (c checkItem) fail(format string, a ...interface{}) checkItem {
+	c.Status, c.Message = checkFail, fmt.Sprintf(format, a...)
+	return c
+}
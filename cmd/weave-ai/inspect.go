@@ -0,0 +1,224 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+	"github.com/weave-ai/weave-ai/pkg/gguf"
+	"github.com/weave-ai/weave-ai/pkg/oci"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [file|model]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Print the GGUF metadata of a model",
+	Long: `Print the architecture, context length, quantization, parameter count and
+metadata of a GGUF model. Models that are not pulled are read from their registry,
+only the beginning of the model is downloaded.
+
+# Inspect a GGUF file
+weave-ai inspect ./my-llama.Q4_K_M.gguf
+
+# Inspect a model of the catalog
+weave-ai inspect zephyr-7b-beta
+
+# Inspect a model OCI as JSON
+weave-ai inspect ghcr.io/weave-ai/models/zephyr-7b-beta-8k:v1.0.0-q5km-gguf -o json
+`,
+	RunE: inspectCmdRun,
+}
+
+var inspectFlags struct {
+	output   string
+	insecure bool
+}
+
+func init() {
+	inspectCmd.Flags().StringVarP(&inspectFlags.output, "output", "o", "table", "output format, one of: table|json")
+	inspectCmd.Flags().BoolVar(&inspectFlags.insecure, "insecure", false, "allows reading from registries over plain HTTP")
+	rootCmd.AddCommand(inspectCmd)
+}
+
+func inspectCmdRun(cmd *cobra.Command, args []string) error {
+	if inspectFlags.output != "table" && inspectFlags.output != "json" {
+		return fmt.Errorf("unknown output format %q, expected one of: table|json", inspectFlags.output)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	f, source, err := readModelHeader(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if inspectFlags.output == "json" {
+		metadata := map[string]any{}
+		for _, kv := range f.Metadata {
+			metadata[kv.Key] = kv.Value
+		}
+		data, err := json.MarshalIndent(struct {
+			Source        string         `json:"source"`
+			Version       uint32         `json:"version"`
+			Architecture  string         `json:"architecture"`
+			Name          string         `json:"name,omitempty"`
+			ContextLength uint64         `json:"contextLength,omitempty"`
+			Quantization  string         `json:"quantization,omitempty"`
+			Parameters    uint64         `json:"parameters"`
+			Tensors       int            `json:"tensors"`
+			Metadata      map[string]any `json:"metadata"`
+		}{
+			Source:        source,
+			Version:       f.Version,
+			Architecture:  f.Architecture(),
+			Name:          f.Name(),
+			ContextLength: f.ContextLength(),
+			Quantization:  f.Quantization(),
+			Parameters:    f.ParameterCount(),
+			Tensors:       len(f.Tensors),
+			Metadata:      metadata,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "SOURCE\t%s\n", source)
+	fmt.Fprintf(w, "GGUF VERSION\t%d\n", f.Version)
+	fmt.Fprintf(w, "ARCHITECTURE\t%s\n", f.Architecture())
+	fmt.Fprintf(w, "NAME\t%s\n", f.Name())
+	fmt.Fprintf(w, "CONTEXT LENGTH\t%d\n", f.ContextLength())
+	fmt.Fprintf(w, "QUANTIZATION\t%s\n", f.Quantization())
+	fmt.Fprintf(w, "PARAMETERS\t%s (%s)\n", catalog.FormatParameters(roundParameters(f.ParameterCount())), humanize.Comma(int64(f.ParameterCount())))
+	fmt.Fprintf(w, "TENSORS\t%d\n", len(f.Tensors))
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "KEY\tTYPE\tVALUE\n")
+	for _, kv := range f.Metadata {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", kv.Key, kv.Type, formatGGUFValue(kv.Value))
+	}
+	w.Flush()
+
+	return nil
+}
+
+// readModelHeader reads the GGUF header of a file, of a directory holding a
+// model, of a model in the local cache, or else of a model in its registry.
+func readModelHeader(ctx context.Context, arg string) (*gguf.File, string, error) {
+	if info, err := os.Stat(arg); err == nil {
+		file := arg
+		if info.IsDir() {
+			if file, err = findModelFile(arg); err != nil {
+				return nil, "", err
+			}
+		}
+		f, err := gguf.ReadFile(file)
+		return f, file, err
+	}
+
+	src, err := resolveModelSource(arg)
+	if err != nil {
+		return nil, "", err
+	}
+	dir, err := weaveAIDir("models", src.name, src.tag)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		file, err := findModelFile(dir)
+		if err != nil {
+			return nil, "", err
+		}
+		f, err := gguf.ReadFile(file)
+		return f, file, err
+	}
+
+	client := &oci.Client{Insecure: inspectFlags.insecure}
+	ref, err := client.ParseReference(src.ref)
+	if err != nil {
+		return nil, "", err
+	}
+	logger.Actionf("reading %s from its registry", ref)
+	manifest, _, err := client.Manifest(ctx, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	layer, err := oci.SelectLayer(manifest, oci.ContentMediaType)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s is not a model artifact: %w", ref, err)
+	}
+	blob, err := client.OpenBlob(ctx, ref.Context(), layer)
+	if err != nil {
+		return nil, "", err
+	}
+	defer blob.Close()
+
+	gz, err := gzip.NewReader(blob)
+	if err != nil {
+		return nil, "", err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, "", fmt.Errorf("no GGUF file found in %s", ref)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if hdr.Typeflag == tar.TypeReg && strings.HasSuffix(strings.ToLower(hdr.Name), ".gguf") {
+			f, err := gguf.Read(tr)
+			return f, ref.String() + "#" + hdr.Name, err
+		}
+	}
+}
+
+// roundParameters rounds a parameter count to the way models are named,
+// e.g. 7241732096 to 7.2B.
+func roundParameters(n uint64) int64 {
+	switch {
+	case n >= 1e9:
+		return int64(n/1e8) * 1e8
+	case n >= 1e6:
+		return int64(n/1e5) * 1e5
+	}
+	return int64(n)
+}
+
+func formatGGUFValue(v any) string {
+	switch v := v.(type) {
+	case *gguf.Array:
+		if v.Len > 8 {
+			return fmt.Sprintf("[%d %s values]", v.Len, v.Type)
+		}
+		var values []string
+		for _, item := range v.Values {
+			values = append(values, formatGGUFValue(item))
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case string:
+		s := strings.ReplaceAll(v, "\n", `\n`)
+		if len(s) > 60 {
+			s = s[:57] + "..."
+		}
+		return s
+	default:
+		return fmt.Sprint(v)
+	}
+}
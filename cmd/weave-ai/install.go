@@ -25,12 +25,16 @@ var installCmd = &cobra.Command{
 	Long: fmt.Sprintf(`
 # Install the Weave AI controllers
 weave-ai install
+
+# Install on a cluster without network access, from a bundle delivered out of band
+weave-ai install --from-bundle ./weave-ai-bundle.tar
 `),
 	RunE: installCmdRun,
 }
 
 var installFlags struct {
 	version           string
+	fromBundle        string
 	export            bool
 	withModelCatalog  bool
 	withDefaultTenant bool
@@ -39,6 +43,7 @@ var installFlags struct {
 
 func init() {
 	installCmd.Flags().StringVarP(&installFlags.version, "version", "v", Version, "version of Weave AI to install")
+	installCmd.Flags().StringVar(&installFlags.fromBundle, "from-bundle", "", "install from the manifests of a bundle, a directory or a tar archive, without network access")
 	installCmd.Flags().BoolVar(&installFlags.export, "export", false, "export manifests instead of installing")
 	installCmd.Flags().BoolVar(&installFlags.withModelCatalog, "with-model-catalog", true, "install the model catalog")
 	installCmd.Flags().BoolVar(&installFlags.withDefaultTenant, "with-default-tenant", true, "install the default tenant")
@@ -50,11 +55,14 @@ func init() {
 func installCmdRun(cmd *cobra.Command, args []string) error {
 	if installFlags.export {
 		logger.stderr = io.Discard
+		// errors are still reported once the command returns
+		defer func() { logger.stderr = os.Stderr }()
 	}
 
 	if err := installControllers(
 		installFlags.export,
 		installFlags.version,
+		installFlags.fromBundle,
 		installFlags.withModelCatalog,
 		installFlags.withDefaultTenant,
 		installFlags.defaultTenantNs); err != nil {
@@ -111,53 +119,10 @@ func verifyTheInstallation() error {
 	return nil
 }
 
-func installControllers(export bool, version string, withModelCatalog bool, withDefaultTenant bool, defaultTenantNs string) error {
+func installControllers(export bool, version string, fromBundle string, withModelCatalog bool, withDefaultTenant bool, defaultTenantNs string) error {
 	logger.Generatef("generating manifests")
 
-	var tpl bytes.Buffer
-	t, err := template.New("template").Parse(installTemplate)
-	if err != nil {
-		return err
-	}
-
-	if err := t.Execute(&tpl, struct {
-		WithModelCatalog  bool
-		WithDefaultTenant bool
-		Version           string
-	}{
-		WithModelCatalog:  withModelCatalog,
-		WithDefaultTenant: withDefaultTenant,
-		Version:           version,
-	}); err != nil {
-		return err
-	}
-
-	// Use Kustomize (krusty) to build the kustomization
-	fSys := filesys.MakeFsInMemory()
-	kustomizationPath := "/app/kustomization.yaml"
-	fSys.WriteFile(kustomizationPath, tpl.Bytes())
-
-	namespacePath := "/app/namespace.yaml"
-	fSys.WriteFile(namespacePath, []byte(fmt.Sprintf(namespaceTemplate, *kubeconfigArgs.Namespace)))
-
-	if withDefaultTenant {
-		defaultTenant := "/app/default_tenant.yaml"
-		fSys.WriteFile(defaultTenant, []byte(fmt.Sprintf(defaultTenantTemplate,
-			defaultTenantNs,
-			defaultTenantNs,
-			defaultTenantNs)))
-	}
-
-	opts := krusty.MakeDefaultOptions()
-	opts.Reorder = krusty.ReorderOptionLegacy
-	k := krusty.MakeKustomizer(opts)
-
-	m, err := k.Run(fSys, "/app")
-	if err != nil {
-		return err
-	}
-
-	yamlOutput, err := m.AsYaml()
+	yamlOutput, err := buildInstallManifests(version, fromBundle, withModelCatalog, withDefaultTenant, defaultTenantNs)
 	if err != nil {
 		return err
 	}
@@ -174,6 +139,14 @@ func installControllers(export bool, version string, withModelCatalog bool, with
 	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
 	defer cancelFn()
 
+	if withModelCatalog {
+		// re-installing must not suspend the models activated since
+		yamlOutput, err = keepActivatedModels(ctx, yamlOutput, false)
+		if err != nil {
+			return fmt.Errorf("install failed: %w", err)
+		}
+	}
+
 	applyOutput, err := utils.Apply(ctx, kubeconfigArgs, kubeclientOptions, yamlOutput, func(e ssa.ChangeSetEntry) (wait bool) {
 		wait = true
 		if e.ObjMetadata.GroupKind.Kind == "OCIRepository" {
@@ -188,3 +161,61 @@ func installControllers(export bool, version string, withModelCatalog bool, with
 
 	return nil
 }
+
+// buildInstallManifests builds the manifests applied by install, uninstall
+// rebuilds them to know what to delete.
+func buildInstallManifests(version string, fromBundle string, withModelCatalog bool, withDefaultTenant bool, defaultTenantNs string) ([]byte, error) {
+	controllerVersion := defaultLMControllerVersion(version)
+
+	// Use Kustomize (krusty) to build the kustomization
+	fSys := filesys.MakeFsInMemory()
+	controllerManifests, modelCatalog, err := writeInstallSources(fSys, "/app", version, controllerVersion, fromBundle, withModelCatalog)
+	if err != nil {
+		return nil, err
+	}
+
+	var tpl bytes.Buffer
+	t, err := template.New("template").Parse(installTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.Execute(&tpl, struct {
+		WithModelCatalog    bool
+		WithDefaultTenant   bool
+		ControllerManifests []string
+		ModelCatalog        string
+	}{
+		WithModelCatalog:    withModelCatalog,
+		WithDefaultTenant:   withDefaultTenant,
+		ControllerManifests: controllerManifests,
+		ModelCatalog:        modelCatalog,
+	}); err != nil {
+		return nil, err
+	}
+
+	kustomizationPath := "/app/kustomization.yaml"
+	fSys.WriteFile(kustomizationPath, tpl.Bytes())
+
+	namespacePath := "/app/namespace.yaml"
+	fSys.WriteFile(namespacePath, []byte(fmt.Sprintf(namespaceTemplate, *kubeconfigArgs.Namespace)))
+
+	if withDefaultTenant {
+		var defaultTenant bytes.Buffer
+		if err := exportObjects(&defaultTenant, newTenantRBAC(defaultTenantNs, "default", nil)...); err != nil {
+			return nil, err
+		}
+		fSys.WriteFile("/app/default_tenant.yaml", defaultTenant.Bytes())
+	}
+
+	opts := krusty.MakeDefaultOptions()
+	opts.Reorder = krusty.ReorderOptionLegacy
+	k := krusty.MakeKustomizer(opts)
+
+	m, err := k.Run(fSys, "/app")
+	if err != nil {
+		return nil, err
+	}
+
+	return m.AsYaml()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/weave-ai/weave-ai/manifests"
+	"github.com/weave-ai/weave-ai/models"
+	"sigs.k8s.io/kustomize/api/filesys"
+)
+
+const lmControllerReleaseURL = "https://github.com/weave-ai/lm-controller/releases/download/%s/%s"
+
+// lmControllerManifests are the files of an lm-controller release.
+var lmControllerManifests = []string{
+	"lm-controller.crds.yaml",
+	"lm-controller.rbac.yaml",
+	"lm-controller.deployment.yaml",
+}
+
+const modelCatalogManifest = "model-catalog.yaml"
+
+// bundleManifestsDir is where a bundle keeps the manifests, a directory
+// holding the files directly is accepted as well.
+const bundleManifestsDir = "manifests"
+
+// writeInstallSources writes the controller manifests and the model catalog
+// into fSys next to the install kustomization, and returns how the
+// kustomization refers to them. The release of the CLI is installed from
+// the manifests embedded in it, and any release from a bundle, without
+// touching the network. Only another release asked for with --version and
+// missing from the CLI is downloaded from GitHub.
+func writeInstallSources(fSys filesys.FileSystem, dir, version, controllerVersion, fromBundle string, withModelCatalog bool) (controller []string, catalog string, err error) {
+	if fromBundle != "" {
+		files, err := readBundleManifests(fromBundle)
+		if err != nil {
+			return nil, "", err
+		}
+		names := lmControllerManifests
+		if withModelCatalog {
+			names = append(names[:len(names):len(names)], modelCatalogManifest)
+		}
+		for _, name := range names {
+			data, ok := files[name]
+			if !ok {
+				return nil, "", fmt.Errorf("bundle %s has no %s", fromBundle, path.Join(bundleManifestsDir, name))
+			}
+			if err := fSys.WriteFile(path.Join(dir, name), data); err != nil {
+				return nil, "", err
+			}
+		}
+		if withModelCatalog {
+			catalog = modelCatalogManifest
+		}
+		return lmControllerManifests, catalog, nil
+	}
+
+	ownRelease := strings.TrimPrefix(version, "v") == strings.TrimPrefix(Version, "v")
+	embedded, err := embeddedLMController(controllerVersion)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(embedded) < len(lmControllerManifests) {
+		if ownRelease {
+			return nil, "", fmt.Errorf("the manifests of lm-controller %s are not embedded in this CLI, build it with make build or install with --from-bundle", controllerVersion)
+		}
+		logger.Actionf("lm-controller %s is not embedded in this CLI, downloading its manifests from GitHub", controllerVersion)
+	}
+	for _, name := range lmControllerManifests {
+		data, ok := embedded[name]
+		if !ok {
+			controller = append(controller, fmt.Sprintf(lmControllerReleaseURL, controllerVersion, name))
+			continue
+		}
+		if err := fSys.WriteFile(path.Join(dir, name), data); err != nil {
+			return nil, "", err
+		}
+		controller = append(controller, name)
+	}
+
+	if !withModelCatalog {
+		return controller, "", nil
+	}
+	if !ownRelease {
+		logger.Actionf("the model catalog of Weave AI %s is not embedded in this CLI, downloading it from GitHub", version)
+		return controller, fmt.Sprintf(modelCatalogReleaseURL, strings.TrimPrefix(version, "v")), nil
+	}
+	if err := writeEmbeddedCatalog(fSys, path.Join(dir, "catalog")); err != nil {
+		return nil, "", err
+	}
+	return controller, "catalog", nil
+}
+
+// writeEmbeddedCatalog copies the kustomization of the model catalog
+// embedded in the CLI into dir.
+func writeEmbeddedCatalog(fSys filesys.FileSystem, dir string) error {
+	return fs.WalkDir(models.FS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := models.FS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return fSys.WriteFile(path.Join(dir, p), data)
+	})
+}
+
+// embeddedLMController returns the manifests of an lm-controller release
+// embedded in the CLI, if any.
+func embeddedLMController(version string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for _, name := range lmControllerManifests {
+		data, err := manifests.LMController.ReadFile(path.Join("lm-controller", version, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+// readBundleManifests reads the manifests of a bundle, either a directory
+// or a tar archive, optionally gzipped.
+func readBundleManifests(bundle string) (map[string][]byte, error) {
+	info, err := os.Stat(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{modelCatalogManifest: true}
+	for _, name := range lmControllerManifests {
+		wanted[name] = true
+	}
+	files := map[string][]byte{}
+
+	if info.IsDir() {
+		for name := range wanted {
+			for _, p := range []string{filepath.Join(bundle, bundleManifestsDir, name), filepath.Join(bundle, name)} {
+				data, err := os.ReadFile(p)
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				files[name] = data
+				break
+			}
+		}
+		return files, nil
+	}
+
+	f, err := os.Open(bundle)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle %s: %w", bundle, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle %s: %w", bundle, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		name = strings.TrimPrefix(name, bundleManifestsDir+"/")
+		if !wanted[name] {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s from bundle %s: %w", hdr.Name, bundle, err)
+		}
+		files[name] = data
+	}
+	return files, nil
+}
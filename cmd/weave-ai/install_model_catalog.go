@@ -1,8 +1,220 @@
 package main
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fluxcd/pkg/ssa"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const modelCatalogReleaseURL = "https://github.com/weave-ai/weave-ai/releases/download/v%s/model-catalog.yaml"
 
 var installModelCatalogCmd = &cobra.Command{
 	Use:   "install-model-catalog",
+	Args:  cobra.NoArgs,
 	Short: "Install the model catalog for Weave AI ",
+	Long: `Install or update the model catalog. Models that were activated keep
+running, only the models that are new to the cluster arrive suspended.
+
+# Install the model catalog released with this version of the CLI
+weave-ai install-model-catalog
+
+# Install the model catalog of a given release
+weave-ai install-model-catalog --version 0.10.0
+
+# Install the model catalog from a URL, either a YAML file or a kustomize remote target
+weave-ai install-model-catalog --url https://github.com/weave-ai/weave-ai//models
+
+# Install the model catalog from a local checkout
+weave-ai install-model-catalog --path ./models
+
+# Export the model catalog manifests
+weave-ai install-model-catalog --export > model-catalog.yaml
+`,
+	RunE: installModelCatalogCmdRun,
+}
+
+var installModelCatalogFlags struct {
+	version string
+	url     string
+	path    string
+	export  bool
+}
+
+func init() {
+	installModelCatalogCmd.Flags().StringVarP(&installModelCatalogFlags.version, "version", "v", Version, "release of the model catalog to install")
+	installModelCatalogCmd.Flags().StringVar(&installModelCatalogFlags.url, "url", "", "URL of the model catalog, a YAML file or a kustomize remote target")
+	installModelCatalogCmd.Flags().StringVar(&installModelCatalogFlags.path, "path", "", "local directory holding the kustomization of the model catalog")
+	installModelCatalogCmd.Flags().BoolVar(&installModelCatalogFlags.export, "export", false, "export manifests instead of installing")
+	rootCmd.AddCommand(installModelCatalogCmd)
+}
+
+func installModelCatalogCmdRun(cmd *cobra.Command, args []string) error {
+	flags := installModelCatalogFlags
+	if flags.url != "" && flags.path != "" {
+		return fmt.Errorf("--url and --path are mutually exclusive")
+	}
+	if cmd.Flags().Changed("version") && (flags.url != "" || flags.path != "") {
+		return fmt.Errorf("--version can't be used with --url or --path")
+	}
+	if flags.export {
+		logger.stderr = io.Discard
+	}
+
+	logger.Generatef("generating manifests")
+	manifests, err := buildModelCatalog(flags.version, flags.url, flags.path)
+	if err != nil {
+		return err
+	}
+	logger.Successf("manifests build completed")
+
+	if flags.export {
+		fmt.Print(string(manifests))
+		return nil
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	manifests, err = keepActivatedModels(ctx, manifests, true)
+	if err != nil {
+		return err
+	}
+
+	logger.Actionf("installing the model catalog")
+	applyOutput, err := utils.Apply(ctx, kubeconfigArgs, kubeclientOptions, manifests, func(e ssa.ChangeSetEntry) bool {
+		// suspended models never become ready
+		return e.ObjMetadata.GroupKind.Kind != sourcev1b2.OCIRepositoryKind
+	})
+	if err != nil {
+		return fmt.Errorf("install failed: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, applyOutput)
+
+	logger.Successf("model catalog installed")
+	return nil
+}
+
+// buildModelCatalog renders the model catalog from a local kustomize
+// directory, from a URL, or else from the release of the given version. The
+// catalog of the release of the CLI is embedded in it, only other releases
+// are downloaded.
+func buildModelCatalog(version, url, path string) ([]byte, error) {
+	k := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+
+	if path != "" {
+		dir, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		m, err := k.Run(filesys.MakeFsOnDisk(), dir)
+		if err != nil {
+			return nil, fmt.Errorf("building the model catalog in %s failed: %w", path, err)
+		}
+		return m.AsYaml()
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	if url == "" && strings.TrimPrefix(version, "v") == strings.TrimPrefix(Version, "v") {
+		if err := writeEmbeddedCatalog(fSys, "/app"); err != nil {
+			return nil, err
+		}
+		m, err := k.Run(fSys, "/app")
+		if err != nil {
+			return nil, fmt.Errorf("building the embedded model catalog failed: %w", err)
+		}
+		return m.AsYaml()
+	}
+
+	if url == "" {
+		url = fmt.Sprintf(modelCatalogReleaseURL, strings.TrimPrefix(version, "v"))
+	}
+	kustomization := fmt.Sprintf("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n- %q\n", url)
+	if err := fSys.WriteFile("/app/kustomization.yaml", []byte(kustomization)); err != nil {
+		return nil, err
+	}
+	m, err := k.Run(fSys, "/app")
+	if err != nil {
+		return nil, fmt.Errorf("building the model catalog from %s failed: %w", url, err)
+	}
+	return m.AsYaml()
+}
+
+// keepActivatedModels sets suspend: false on the models of the manifests
+// that are already active in the cluster, so that applying the catalog
+// doesn't suspend them again. When withNamespaces is set, the namespaces of
+// the models are added to the manifests, as the catalog doesn't create them.
+func keepActivatedModels(ctx context.Context, manifests []byte, withNamespaces bool) ([]byte, error) {
+	objs, err := ssa.ReadObjects(strings.NewReader(string(manifests)))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return nil, err
+	}
+	// no match for the kind means that Flux isn't installed yet, so no
+	// model can be active
+	existing := &sourcev1b2.OCIRepositoryList{}
+	if err := client.List(ctx, existing, runtimeclient.MatchingLabels{
+		artifactKindLabel: languageModelArtifactKind,
+	}); err != nil && !apimeta.IsNoMatchError(err) {
+		return nil, err
+	}
+	active := map[types.NamespacedName]bool{}
+	for i := range existing.Items {
+		if !existing.Items[i].Spec.Suspend {
+			active[runtimeclient.ObjectKeyFromObject(&existing.Items[i])] = true
+		}
+	}
+
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, obj := range objs {
+		if obj.GetKind() != sourcev1b2.OCIRepositoryKind {
+			continue
+		}
+		if ns := obj.GetNamespace(); ns != "" && !seen[ns] {
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+		key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		if !active[key] {
+			continue
+		}
+		if err := unstructured.SetNestedField(obj.Object, false, "spec", "suspend"); err != nil {
+			return nil, err
+		}
+		logger.Actionf("keeping model %s active", key)
+	}
+
+	if withNamespaces {
+		for _, ns := range namespaces {
+			u := &unstructured.Unstructured{}
+			u.SetAPIVersion("v1")
+			u.SetKind("Namespace")
+			u.SetName(ns)
+			objs = append(objs, u)
+		}
+	}
+
+	out, err := ssa.ObjectsToYAML(objs)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
 }
@@ -1,19 +1,76 @@
 package main
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // There are two components currently in the Flamingo project:
 // - the LM controller
 // - the BRS controller
 
+// lmControllerVersions maps the releases of the CLI to the release of the
+// lm-controller they were tested with. Add the new release here when the
+// controller is bumped.
+var lmControllerVersions = map[string]string{
+	"0.11.0": "v0.9.0",
+	"0.12.0": "v0.10.0",
+}
+
+// latestLMControllerVersion is installed by development builds, and by
+// releases missing from lmControllerVersions.
+const latestLMControllerVersion = "v0.10.0"
+
+// lookupLMControllerVersion returns the lm-controller release matching a
+// release of the CLI, or the latest one when the release isn't known.
+func lookupLMControllerVersion(version string) (string, bool) {
+	if version == "dev" {
+		return latestLMControllerVersion, true
+	}
+	if v, ok := lmControllerVersions[strings.TrimPrefix(version, "v")]; ok {
+		return v, true
+	}
+	return latestLMControllerVersion, false
+}
+
+// resolveLMControllerVersion is lookupLMControllerVersion for versions asked
+// for explicitly, such as upgrade --version, which must be known.
+func resolveLMControllerVersion(version string) (string, error) {
+	if v, ok := lookupLMControllerVersion(version); ok {
+		return v, nil
+	}
+	var known []string
+	for v := range lmControllerVersions {
+		known = append(known, v)
+	}
+	sort.Strings(known)
+	return "", fmt.Errorf("no lm-controller release is known for version %s, expected one of %s", version, strings.Join(known, ", "))
+}
+
+// defaultLMControllerVersion is lookupLMControllerVersion for the version of
+// the CLI, so that a release cut without updating lmControllerVersions
+// still installs.
+func defaultLMControllerVersion(version string) string {
+	v, ok := lookupLMControllerVersion(version)
+	if !ok {
+		logger.Warningf("no lm-controller release is known for version %s, using the latest one %s", version, v)
+	}
+	return v
+}
+
+// installTemplate lists the controller manifests and the model catalog
+// either as release URLs or as files written next to the kustomization.
 const installTemplate = `
 apiVersion: kustomize.config.k8s.io/v1beta1
 kind: Kustomization
 resources:
 - namespace.yaml
-- "https://github.com/weave-ai/lm-controller/releases/download/v0.9.0/lm-controller.crds.yaml"
-- "https://github.com/weave-ai/lm-controller/releases/download/v0.9.0/lm-controller.rbac.yaml"
-- "https://github.com/weave-ai/lm-controller/releases/download/v0.9.0/lm-controller.deployment.yaml"
+{{- range .ControllerManifests }}
+- {{ printf "%q" . }}
+{{- end }}
 {{- if .WithModelCatalog }}
-- "https://github.com/weave-ai/weave-ai/releases/download/v{{ .Version }}/model-catalog.yaml"
+- {{ printf "%q" .ModelCatalog }}
 {{- end }}
 {{- if .WithDefaultTenant }}
 - default_tenant.yaml
@@ -27,39 +84,6 @@ metadata:
   name: %s
 `
 
-var defaultTenantTemplate = `
----
-apiVersion: rbac.authorization.k8s.io/v1
-kind: Role
-metadata:
-  name: lm-tenant-role
-  namespace: %s
-rules:
-  - apiGroups: ["apps"]
-    resources: ["deployments", "replicasets"]
-    verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
-  - apiGroups: [""]
-    resources: ["services", "persistentvolumeclaims"]
-    verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
-  - apiGroups: ["serving.knative.dev"]
-    resources: ["services"]
-    verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
----
-apiVersion: rbac.authorization.k8s.io/v1
-kind: RoleBinding
-metadata:
-  name: lm-tenant-role-binding
-  namespace: %s
-subjects:
-  - kind: ServiceAccount
-    name: default
-    namespace: %s
-roleRef:
-  kind: Role
-  name: lm-tenant-role
-  apiGroup: rbac.authorization.k8s.io
-`
-
 const defaultClusterSecretTemplate = `
 ---
 `
@@ -0,0 +1,26 @@
+package main
+
+const (
+	// modelNamespaceLabel and modelLabel record on a LanguageModel which
+	// OCIRepository of the model catalog it was created from.
+	modelNamespaceLabel = "ai.contrib.fluxcd.io/model-namespace"
+	modelLabel          = "ai.contrib.fluxcd.io/model"
+
+	// artifactKindLabel marks the OCIRepositories that hold a model, as
+	// opposed to other Flux sources living in the same namespace.
+	artifactKindLabel         = "ai.contrib.fluxcd.io/artifact-kind"
+	languageModelArtifactKind = "language-model"
+
+	// languageModelLabel ties the chat UI objects to their LanguageModel.
+	languageModelLabel = "ai.contrib.fluxcd.io/language-model"
+
+	// lmNameLabel and lmNamespaceLabel are set by lm-controller on the engine
+	// objects it applies for a LanguageModel, the way Flux labels the objects
+	// of a Kustomization.
+	lmNameLabel      = "languagemodel.ai.contrib.fluxcd.io/name"
+	lmNamespaceLabel = "languagemodel.ai.contrib.fluxcd.io/namespace"
+
+	// tenantLabel marks the objects setup-tenant created for a tenant, with
+	// the namespace of the tenant as value.
+	tenantLabel = "ai.contrib.fluxcd.io/tenant"
+)
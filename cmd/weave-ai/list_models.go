@@ -1,77 +1,197 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/dustin/go-humanize"
 	meta2 "github.com/fluxcd/pkg/apis/meta"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/models"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
 	"github.com/weave-ai/weave-ai/pkg/utils"
+	"io"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
 	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"text/tabwriter"
+	"strings"
+	"time"
 )
 
 var listModelsCmd = &cobra.Command{
 	Use:     "list-models",
 	Aliases: []string{"list-model", "models"},
 	Short:   "List all OCI Language Model resources",
-	RunE:    listModelsCmdRun,
+	Long: `
+# List the models installed in the cluster.
+weave-ai list-models -A
+
+# Browse the model catalog built into the CLI, no cluster needed.
+weave-ai list-models --catalog
+
+# List the names and digests of the active models as JSON.
+weave-ai list-models --filter status=active -o json
+
+# Browse a catalog index published elsewhere.
+weave-ai list-models --catalog-url https://raw.githubusercontent.com/weave-ai/weave-ai/main/models/_index/index.json
+`,
+	RunE: listModelsCmdRun,
 }
 
 var listModelsFlags struct {
-	all bool
+	all        bool
+	catalog    bool
+	catalogURL string
+	filters    []string
+	output     string
 }
 
 func init() {
 	listModelsCmd.Flags().BoolVarP(&listModelsFlags.all, "all", "A", false, "Show models from all namespaces")
+	listModelsCmd.Flags().BoolVar(&listModelsFlags.catalog, "catalog", false, "Show the models of the catalog built into the CLI instead of the cluster")
+	listModelsCmd.Flags().StringVar(&listModelsFlags.catalogURL, "catalog-url", "", "Show the models of the catalog index at this URL instead of the cluster")
+	listModelsCmd.Flags().StringArrayVar(&listModelsFlags.filters, "filter", nil, "Show only the models matching the filter, e.g. family=mistral or context>=32k (see weave-ai search --help)")
+	listModelsCmd.Flags().StringVarP(&listModelsFlags.output, "output", "o", "", "Output format, "+outputFlagUsage)
 	rootCmd.AddCommand(listModelsCmd)
 }
 
 func listModelsCmdRun(cmd *cobra.Command, args []string) error {
-	cli, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err := validateOutputFormat(listModelsFlags.output); err != nil {
+		return err
+	}
+	filter, err := catalog.ParseFilter(listModelsFlags.filters...)
 	if err != nil {
 		return err
 	}
 
+	if listModelsFlags.catalog || listModelsFlags.catalogURL != "" {
+		return listCatalogModels(listModelsFlags.catalogURL, filter, listModelsFlags.output)
+	}
+
 	namespace := *kubeconfigArgs.Namespace
 	if listModelsFlags.all {
 		namespace = ""
 	}
+	return listClusterModels(namespace, filter, listModelsFlags.output)
+}
+
+func listClusterModels(namespace string, filter *catalog.Filter, output string) error {
+	cli, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
 
 	models := &sourcev1b2.OCIRepositoryList{}
-	ctx, _ := context.WithTimeout(context.Background(), rootArgs.timeout)
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
 	if err := cli.List(ctx, models, client.InNamespace(namespace), client.MatchingLabels{
 		"ai.contrib.fluxcd.io/artifact-kind": "language-model",
 	}); err != nil {
 		return err
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintf(w, "NAME\tVERSION\tFAMILY\tSTATUS\tCREATED\n")
-	for _, model := range models.Items {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			model.Namespace+"/"+model.Name,
-			model.Spec.Reference.Tag,
-			getFamily(model),
-			getStatus(model),
-			humanize.Time(model.CreationTimestamp.Time),
-		)
+	var items []modelItem
+	for i := range models.Items {
+		model := &models.Items[i]
+		entry, err := catalog.NewIndexEntry(model)
+		if err != nil {
+			// show the model anyway, without the invalid fields
+			logger.Warningf("%v", err)
+		}
+		status := getStatus(*model)
+		item := modelItem{
+			IndexEntry: *entry,
+			Namespace:  model.Namespace,
+			Status:     statusFilterValue(status),
+			Created:    model.CreationTimestamp.Time,
+			statusText: status,
+		}
+		if !filter.Match(item.IndexEntry, item.Status) {
+			continue
+		}
+		item.LastReconcile = lastReconcileTime(model.Status.ReconcileRequestStatus)
+		if model.Status.Artifact != nil {
+			item.ArtifactDigest = model.Status.Artifact.Digest
+			if updated := model.Status.Artifact.LastUpdateTime.Time; item.LastReconcile == nil || updated.After(*item.LastReconcile) {
+				item.LastReconcile = &updated
+			}
+		}
+		// the Ready condition only moves when readiness flips, not on every reconcile
+		if cond := meta.FindStatusCondition(model.Status.Conditions, meta2.ReadyCondition); cond != nil && cond.Status == metav1.ConditionTrue {
+			item.ReadySince = &cond.LastTransitionTime.Time
+		}
+		items = append(items, item)
 	}
-	w.Flush()
 
-	return nil
+	return printList(os.Stdout, output, items, modelColumns, func(item modelItem) string {
+		return item.Namespace + "/" + item.Name
+	})
 }
 
-func getFamily(model sourcev1b2.OCIRepository) any {
-	if model.Status.Artifact == nil {
+// modelItem is a model in the cluster as printed by list-models and search.
+type modelItem struct {
+	catalog.IndexEntry
+	Namespace      string     `json:"namespace"`
+	Status         string     `json:"status"`
+	ArtifactDigest string     `json:"artifactDigest,omitempty"`
+	LastReconcile  *time.Time `json:"lastReconcile,omitempty"`
+	ReadySince     *time.Time `json:"readySince,omitempty"`
+	Created        time.Time  `json:"created"`
+
+	statusText string
+}
+
+var modelColumns = []column[modelItem]{
+	{header: "NAME", value: func(m modelItem) string { return m.Namespace + "/" + m.Name }},
+	{header: "VERSION", value: func(m modelItem) string { return m.Tag }},
+	{header: "FAMILY", value: func(m modelItem) string { return m.Family }},
+	{header: "STATUS", value: func(m modelItem) string { return m.statusText }},
+	{header: "CREATED", value: func(m modelItem) string { return humanize.Time(m.Created) }},
+	{header: "URL", wide: true, value: func(m modelItem) string { return m.URL }},
+	{header: "DIGEST", wide: true, value: func(m modelItem) string { return m.ArtifactDigest }},
+	{header: "LAST-RECONCILE", wide: true, value: func(m modelItem) string { return formatTime(m.LastReconcile) }},
+	{header: "READY-SINCE", wide: true, value: func(m modelItem) string { return formatTime(m.ReadySince) }},
+}
+
+var catalogColumns = []column[catalog.IndexEntry]{
+	{header: "NAME", value: func(m catalog.IndexEntry) string { return m.Name }},
+	{header: "VERSION", value: func(m catalog.IndexEntry) string { return m.Version }},
+	{header: "FAMILY", value: func(m catalog.IndexEntry) string { return m.Family }},
+	{header: "QUANTIZATION", value: func(m catalog.IndexEntry) string { return m.Quantization }},
+	{header: "CONTEXT", value: func(m catalog.IndexEntry) string { return formatContextLength(m.ContextLength) }},
+	{header: "PARAMETERS", value: func(m catalog.IndexEntry) string { return catalog.FormatParameters(m.Parameters) }},
+	{header: "LICENSE", value: func(m catalog.IndexEntry) string { return m.License }},
+	{header: "URL", wide: true, value: func(m catalog.IndexEntry) string { return m.URL }},
+	{header: "TAG", wide: true, value: func(m catalog.IndexEntry) string { return m.Tag }},
+	{header: "DIGEST", wide: true, value: func(m catalog.IndexEntry) string { return m.Digest }},
+}
+
+// lastReconcileTime returns when the controller last handled a reconcile
+// request, as set by flux reconcile and the reconcile.fluxcd.io/requestedAt
+// annotation, if any.
+func lastReconcileTime(status meta2.ReconcileRequestStatus) *time.Time {
+	t, err := time.Parse(time.RFC3339Nano, status.LastHandledReconcileAt)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
 		return ""
 	}
+	return humanize.Time(*t)
+}
 
-	return model.Status.Artifact.Metadata["ai.contrib.fluxcd.io/family"]
+// statusFilterValue turns a status printed by getStatus, e.g. "* ACTIVE"
+// or "NOT READY", into the value of a status filter, e.g. active or not-ready.
+func statusFilterValue(status string) string {
+	status = strings.TrimPrefix(status, "* ")
+	return strings.ToLower(strings.ReplaceAll(status, " ", "-"))
 }
 
 func getStatus(model sourcev1b2.OCIRepository) string {
@@ -98,3 +218,61 @@ func getStatus(model sourcev1b2.OCIRepository) string {
 
 	return "UNKNOWN"
 }
+
+// listCatalogModels prints the models of the catalog index embedded in the
+// CLI, or of the one at url when it is set, that match the filter.
+func listCatalogModels(url string, filter *catalog.Filter, output string) error {
+	if filter.Uses("status") {
+		return fmt.Errorf("the status filter needs a cluster, catalog models have no status")
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	index, err := loadCatalogIndex(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	var items []catalog.IndexEntry
+	for _, model := range index.Models {
+		if filter.Match(model, "") {
+			items = append(items, model)
+		}
+	}
+
+	return printList(os.Stdout, output, items, catalogColumns, func(item catalog.IndexEntry) string {
+		return item.Name
+	})
+}
+
+func loadCatalogIndex(ctx context.Context, url string) (*catalog.Index, error) {
+	if url == "" {
+		return catalog.ReadIndex(bytes.NewReader(models.Index))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching catalog index %s failed: %s", url, resp.Status)
+	}
+	return catalog.ReadIndex(io.LimitReader(resp.Body, 10<<20))
+}
+
+func formatContextLength(n int64) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n%1024 == 0:
+		return fmt.Sprintf("%dk", n/1024)
+	default:
+		return fmt.Sprint(n)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LLMs started with run --local are served by llamafile, a single binary
+// build of llama.cpp that runs on Linux, macOS and Windows.
+const (
+	llamafileVersion = "0.4"
+	llamafileURL     = "https://github.com/Mozilla-Ocho/llamafile/releases/download/" + llamafileVersion + "/llamafile-" + llamafileVersion
+)
+
+// localInstance is an LLM running on the workstation. It is recorded in
+// ~/.weave-ai/run/<name>.json so that ps and rm can find it later.
+type localInstance struct {
+	Name      string    `json:"name"`
+	Model     string    `json:"model"`
+	ModelFile string    `json:"modelFile"`
+	PID       int       `json:"pid"`
+	StartTime string    `json:"startTime"`
+	Port      int       `json:"port"`
+	Threads   int       `json:"threads"`
+	LogFile   string    `json:"logFile"`
+	Created   time.Time `json:"created"`
+}
+
+func (i *localInstance) URL() string {
+	return fmt.Sprintf("http://localhost:%d", i.Port)
+}
+
+// Running tells whether the process of the LLM still runs. The start time
+// of the process is compared too, as after a reboot the PID may belong to
+// an unrelated process.
+func (i *localInstance) Running() bool {
+	if i.StartTime == "" || !processAlive(i.PID) {
+		return false
+	}
+	started, err := processStartTime(i.PID)
+	return err == nil && started == i.StartTime
+}
+
+func localInstanceFile(name string) (string, error) {
+	return weaveAIDir("run", name+".json")
+}
+
+func saveLocalInstance(instance *localInstance) error {
+	file, err := localInstanceFile(instance.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(instance, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+func loadLocalInstance(name string) (*localInstance, error) {
+	file, err := localInstanceFile(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("local LLM %s not found", name)
+		}
+		return nil, err
+	}
+	instance := &localInstance{}
+	if err := json.Unmarshal(data, instance); err != nil {
+		return nil, fmt.Errorf("invalid state of local LLM %s: %w", name, err)
+	}
+	return instance, nil
+}
+
+func listLocalInstances() ([]*localInstance, error) {
+	dir, err := weaveAIDir("run")
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []*localInstance
+	for _, file := range files {
+		instance, err := loadLocalInstance(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name < instances[j].Name
+	})
+	return instances, nil
+}
+
+// removeLocalInstance stops the process of a local LLM and forgets about it.
+func removeLocalInstance(instance *localInstance) error {
+	logger.Actionf("removing local LLM %s", instance.Name)
+	if !instance.Running() && processAlive(instance.PID) {
+		logger.Warningf("pid %d is no longer local LLM %s, forgetting it", instance.PID, instance.Name)
+	} else if instance.Running() {
+		proc, err := os.FindProcess(instance.PID)
+		if err != nil {
+			return err
+		}
+		if err := proc.Kill(); err != nil && instance.Running() {
+			return fmt.Errorf("stopping local LLM %s (pid %d) failed: %w", instance.Name, instance.PID, err)
+		}
+	}
+
+	file, err := localInstanceFile(instance.Name)
+	if err != nil {
+		return err
+	}
+	for _, f := range []string{file, instance.LogFile} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	logger.Successf("local LLM %s removed", instance.Name)
+	return nil
+}
+
+// ensureLlamafile returns the path of the llamafile binary in
+// ~/.weave-ai/bin, downloading it on first use.
+func ensureLlamafile(ctx context.Context) (string, error) {
+	name := "llamafile"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	bin, err := weaveAIDir("bin", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(bin); err == nil {
+		return bin, nil
+	}
+
+	logger.Actionf("downloading llamafile %s", llamafileVersion)
+	if err := os.MkdirAll(filepath.Dir(bin), 0o755); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, llamafileURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s failed: %s", llamafileURL, resp.Status)
+	}
+
+	tmp := bin + ".partial"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, bin); err != nil {
+		return "", err
+	}
+
+	logger.Successf("llamafile installed to %s", bin)
+	return bin, nil
+}
+
+// findModelFile returns the GGUF file of a model unpacked by pull.
+func findModelFile(dir string) (string, error) {
+	var found string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if found == "" && !d.IsDir() && strings.HasSuffix(strings.ToLower(d.Name()), ".gguf") {
+			found = path
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("no GGUF file found in %s", dir)
+	}
+	return found, nil
+}
@@ -0,0 +1,68 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// detachProcess starts the process in its own session, so that it keeps
+// running after the terminal of the CLI goes away.
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// signal 0 only checks that the process exists
+	return syscall.Kill(pid, syscall.Signal(0)) == nil
+}
+
+// processStartTime identifies when a process started, so that a PID
+// reused by another process can be told apart.
+func processStartTime(pid int) (string, error) {
+	if runtime.GOOS == "linux" {
+		return linuxProcessStartTime(pid)
+	}
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", fmt.Errorf("process %d not found: %w", pid, err)
+	}
+	started := strings.TrimSpace(string(out))
+	if started == "" {
+		return "", fmt.Errorf("process %d not found", pid)
+	}
+	return started, nil
+}
+
+// linuxProcessStartTime returns the boot ID and the start time, in clock
+// ticks since boot, of a process.
+func linuxProcessStartTime(pid int) (string, error) {
+	bootID, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return "", err
+	}
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", err
+	}
+	// the command name may hold spaces, the fields after it don't
+	i := strings.LastIndexByte(string(stat), ')')
+	if i < 0 {
+		return "", fmt.Errorf("invalid /proc/%d/stat", pid)
+	}
+	// fields start at the state, the third one, and the start time is the 22nd
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 20 {
+		return "", fmt.Errorf("invalid /proc/%d/stat", pid)
+	}
+	return strings.TrimSpace(string(bootID)) + "/" + fields[19], nil
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Args:  cobra.ExactArgs(1),
+	Short: "Print the logs of an LLM",
+	Long: `
+# Print the engine logs of the LLM my-llm.
+weave-ai logs my-llm
+
+# Follow the engine logs of my-llm, reattaching when its pod restarts.
+weave-ai logs -f my-llm
+
+# Print the last 100 lines of the chat UI logs of my-llm.
+weave-ai logs --ui --tail=100 my-llm
+
+# Follow the logs written in the last 10 minutes by all engine pods of my-llm.
+weave-ai logs -f --since=10m --all-pods my-llm
+`,
+	RunE: logsCmdRun,
+}
+
+var logsFlags struct {
+	namespace string
+	follow    bool
+	since     time.Duration
+	tail      int64
+	ui        bool
+	allPods   bool
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFlags.follow, "follow", "f", false, "streams the logs and reattaches when a pod restarts or is replaced")
+	logsCmd.Flags().DurationVar(&logsFlags.since, "since", 0, "only prints logs newer than a relative duration like 5s, 2m, or 3h")
+	logsCmd.Flags().Int64Var(&logsFlags.tail, "tail", -1, "number of recent lines to print, -1 prints all of them")
+	logsCmd.Flags().BoolVar(&logsFlags.ui, "ui", false, "prints the logs of the Weave Chat UI instead of the engine")
+	logsCmd.Flags().BoolVar(&logsFlags.allPods, "all-pods", false, "prints the logs of all pods, prefixed with the pod name")
+
+	// TODO use the default namespace from context
+	logsCmd.Flags().StringVarP(&logsFlags.namespace, "namespace", "n", "default", "the namespace of the LLM")
+	rootCmd.AddCommand(logsCmd)
+}
+
+func logsCmdRun(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if !logsFlags.follow {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithTimeout(ctx, rootArgs.timeout)
+		defer cancelFn()
+	}
+
+	return streamLogs(ctx, logOptions{
+		namespace: logsFlags.namespace,
+		name:      args[0],
+		ui:        logsFlags.ui,
+		follow:    logsFlags.follow,
+		since:     logsFlags.since,
+		tail:      logsFlags.tail,
+		allPods:   logsFlags.allPods,
+	}, os.Stdout)
+}
+
+type logOptions struct {
+	namespace string
+	name      string // name of the LLM
+	ui        bool   // read the chat UI container instead of the engine
+	follow    bool
+	since     time.Duration
+	tail      int64
+	allPods   bool
+}
+
+type podLogResult struct {
+	pod  string
+	last time.Time // timestamp of the last line read
+	err  error
+}
+
+// streamLogs copies the logs of the pods of an LLM to out. When following,
+// it keeps watching the pods and reattaches to restarted or replacement
+// pods until ctx is done.
+func streamLogs(ctx context.Context, opts logOptions, out io.Writer) error {
+	config, err := utils.KubeConfig(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	appName, container := opts.name, "engine"
+	if opts.ui {
+		appName, container = opts.name+"-chat-app", "chat-app"
+	}
+
+	// cancelled when giving up on an error that reattaching can't fix
+	ctx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
+
+	w := &lineWriter{out: out}
+	active := map[string]bool{}
+	streamed := map[string]bool{}
+	// pods we already streamed once only get the lines written after the
+	// last one we read, as timestamped by the kubelet
+	resumeFrom := map[string]time.Time{}
+	// pods whose stream failed are retried with an exponential backoff
+	backoff := map[string]time.Duration{}
+	retryAt := map[string]time.Time{}
+	attachedAt := map[string]time.Time{}
+	results := make(chan podLogResult)
+	var wg sync.WaitGroup
+
+	attach := func(pod string) {
+		logOpts := &corev1.PodLogOptions{
+			Container:  container,
+			Follow:     opts.follow,
+			Timestamps: true,
+		}
+		after, resume := resumeFrom[pod]
+		if resume {
+			// SinceTime has a precision of a second, the lines of that second
+			// that were already printed are skipped by their timestamp
+			logOpts.SinceTime = &metav1.Time{Time: after}
+		} else {
+			if opts.since > 0 {
+				seconds := int64(opts.since.Seconds())
+				logOpts.SinceSeconds = &seconds
+			}
+			if opts.tail >= 0 {
+				logOpts.TailLines = &opts.tail
+			}
+		}
+
+		prefix := ""
+		if opts.allPods {
+			prefix = "[" + pod + "] "
+		}
+
+		active[pod] = true
+		attachedAt[pod] = time.Now()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last, err := streamPodLogs(ctx, clientSet, opts.namespace, pod, logOpts, w, prefix, after)
+			select {
+			case results <- podLogResult{pod: pod, last: last, err: err}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	for {
+		pods, err := clientSet.CoreV1().Pods(opts.namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: "app=" + appName,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if !opts.follow || apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) {
+				cancelFn()
+				wg.Wait()
+				return err
+			}
+			logger.Warningf("listing pods of %s/%s failed: %v", opts.namespace, appName, err)
+		} else {
+			candidates := podsWithLogs(pods.Items, container)
+			if !opts.follow && len(candidates) == 0 {
+				return fmt.Errorf("no running pods found for %s/%s", opts.namespace, appName)
+			}
+			if !opts.allPods && len(active) == 0 && len(candidates) > 1 {
+				// wait for the newest pod, even while it is backing off,
+				// rather than falling back to the pod it replaces
+				candidates = candidates[:1]
+			}
+			for _, pod := range candidates {
+				// a terminated container has nothing more to say after its first read
+				if streamed[pod.name] && !pod.running {
+					continue
+				}
+				if !opts.allPods && len(active) > 0 {
+					break
+				}
+				if !active[pod.name] && !time.Now().Before(retryAt[pod.name]) {
+					attach(pod.name)
+				}
+			}
+		}
+
+		if !opts.follow {
+			var firstErr error
+			for range active {
+				select {
+				case res := <-results:
+					if res.err != nil && firstErr == nil {
+						firstErr = fmt.Errorf("pod %s: %w", res.pod, res.err)
+					}
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return firstErr
+		}
+
+		select {
+		case <-ctx.Done():
+		case res := <-results:
+			delete(active, res.pod)
+			streamed[res.pod] = true
+			if !res.last.IsZero() {
+				resumeFrom[res.pod] = res.last
+			}
+			if res.err == nil || ctx.Err() != nil {
+				delete(backoff, res.pod)
+				break
+			}
+			if apierrors.IsForbidden(res.err) || apierrors.IsUnauthorized(res.err) {
+				cancelFn()
+				wg.Wait()
+				return fmt.Errorf("pod %s: %w", res.pod, res.err)
+			}
+			delay := logsRetryDelay(backoff[res.pod], time.Since(attachedAt[res.pod]))
+			backoff[res.pod] = delay
+			retryAt[res.pod] = time.Now().Add(delay)
+			logger.Waitingf("lost the logs of pod %s/%s, reattaching in %s: %v", opts.namespace, res.pod, delay, res.err)
+		case <-time.After(rootArgs.pollInterval):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// maxLogsRetryDelay caps the backoff between attempts to reattach to a pod
+// whose logs can't be read, e.g. while it is in CrashLoopBackOff.
+const maxLogsRetryDelay = time.Minute
+
+// logsRetryDelay doubles the previous delay, starting from the poll
+// interval. A stream that lasted longer than the cap starts over.
+func logsRetryDelay(previous, streamed time.Duration) time.Duration {
+	if previous == 0 || streamed > maxLogsRetryDelay {
+		return rootArgs.pollInterval
+	}
+	if previous*2 > maxLogsRetryDelay {
+		return maxLogsRetryDelay
+	}
+	return previous * 2
+}
+
+type podWithLogs struct {
+	name    string
+	running bool
+}
+
+// podsWithLogs returns the pods whose container has started, newest first,
+// as pods still pulling images or being scheduled have no logs yet.
+func podsWithLogs(pods []corev1.Pod, container string) []podWithLogs {
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+
+	var result []podWithLogs
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != container {
+				continue
+			}
+			if status.State.Running != nil || status.State.Terminated != nil {
+				result = append(result, podWithLogs{name: pod.Name, running: status.State.Running != nil})
+			}
+		}
+	}
+	return result
+}
+
+// streamPodLogs copies the timestamped logs of a pod to w without their
+// timestamps, skipping the lines written up to after, and returns the
+// timestamp of the last line.
+func streamPodLogs(ctx context.Context, clientSet kubernetes.Interface, namespace, pod string, opts *corev1.PodLogOptions, w *lineWriter, prefix string, after time.Time) (time.Time, error) {
+	last := after
+	stream, err := clientSet.CoreV1().Pods(namespace).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return last, err
+	}
+	defer stream.Close()
+
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			timestamp, text, ok := splitLogTimestamp(line)
+			switch {
+			case !ok:
+				w.WriteLine(prefix, line)
+			case timestamp.After(after):
+				w.WriteLine(prefix, text)
+				last = timestamp
+			}
+		}
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return last, err
+		}
+	}
+}
+
+// splitLogTimestamp splits the RFC 3339 timestamp the kubelet prefixes a
+// log line with from the line.
+func splitLogTimestamp(line string) (time.Time, string, bool) {
+	timestamp, text, found := strings.Cut(line, " ")
+	if !found {
+		return time.Time{}, line, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return t, text, true
+}
+
+// lineWriter serializes whole lines coming from several pods so that
+// they don't interleave.
+type lineWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *lineWriter) WriteLine(prefix, line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if prefix != "" && line[len(line)-1] != '\n' {
+		line += "\n"
+	}
+	fmt.Fprint(w.out, prefix+line)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+const outputFlagUsage = "one of: json|yaml|wide|name|go-template=...|custom-columns=..."
+
+// column is a column of the table printed by the list commands. Wide
+// columns are only shown with -o wide.
+type column[T any] struct {
+	header string
+	wide   bool
+	value  func(T) string
+}
+
+// itemList is what -o json, yaml and go-template print. The items are
+// encoded with their JSON field names, which are kept stable for scripts.
+type itemList[T any] struct {
+	Items []T `json:"items"`
+}
+
+// validateOutputFormat fails early on an unknown -o value, before any
+// request is made to the cluster.
+func validateOutputFormat(output string) error {
+	format, arg, _ := strings.Cut(output, "=")
+	switch format {
+	case "", "wide", "json", "yaml", "name":
+		return nil
+	case "go-template", "custom-columns":
+		if arg == "" {
+			return fmt.Errorf("-o %s needs a value, e.g. -o %s=...", format, format)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected %s", output, outputFlagUsage)
+	}
+}
+
+// printList prints items in the format selected with -o.
+func printList[T any](w io.Writer, output string, items []T, columns []column[T], name func(T) string) error {
+	if err := validateOutputFormat(output); err != nil {
+		return err
+	}
+	if items == nil {
+		items = []T{}
+	}
+
+	format, arg, _ := strings.Cut(output, "=")
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(itemList[T]{Items: items}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(itemList[T]{Items: items})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case "name":
+		for _, item := range items {
+			if _, err := fmt.Fprintln(w, name(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "go-template":
+		return printGoTemplate(w, arg, itemList[T]{Items: items})
+	case "custom-columns":
+		return printCustomColumns(w, arg, items)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var headers []string
+	for _, c := range columns {
+		if !c.wide || format == "wide" {
+			headers = append(headers, c.header)
+		}
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, item := range items {
+		var values []string
+		for _, c := range columns {
+			if !c.wide || format == "wide" {
+				values = append(values, c.value(item))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+// toGeneric round-trips v through JSON, so that templates and column paths
+// refer to the JSON field names rather than the Go ones.
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// keep numbers as written, large counts would otherwise print as 4.67e+10
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func printGoTemplate(w io.Writer, text string, v any) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid go-template: %w", err)
+	}
+	data, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// printCustomColumns prints the columns given as HEADER:.field.path pairs
+// separated by commas, e.g. NAME:.name,FAMILY:.family.
+func printCustomColumns[T any](w io.Writer, spec string, items []T) error {
+	type customColumn struct {
+		header string
+		path   []string
+	}
+	var columns []customColumn
+	for _, def := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(def, ":")
+		if !ok || header == "" || !strings.HasPrefix(path, ".") {
+			return fmt.Errorf("invalid custom column %q, expected HEADER:.field", def)
+		}
+		path = strings.TrimPrefix(path, ".")
+		columns = append(columns, customColumn{header: header, path: strings.Split(path, ".")})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var headers []string
+	for _, c := range columns {
+		headers = append(headers, c.header)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, item := range items {
+		data, err := toGeneric(item)
+		if err != nil {
+			return err
+		}
+		var values []string
+		for _, c := range columns {
+			values = append(values, lookupPath(data, c.path))
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+func lookupPath(v any, path []string) string {
+	for _, key := range path {
+		if key == "" {
+			continue
+		}
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "<none>"
+		}
+		if v, ok = m[key]; !ok {
+			return "<none>"
+		}
+	}
+	switch v := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
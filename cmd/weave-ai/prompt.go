@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// confirm asks a yes/no question on the terminal and returns true only
+// when the user explicitly answers yes. A closed stdin counts as a no.
+func confirm(format string, a ...interface{}) bool {
+	fmt.Fprintf(os.Stderr, "? %s [y/N] ", fmt.Sprintf(format, a...))
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stderr)
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	"github.com/spf13/cobra"
+	aiv1a1 "github.com/weave-ai/lm-controller/api/v1alpha1"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var psCmd = &cobra.Command{
+	Use:     "ps",
+	Aliases: []string{"list-lms"},
+	Short:   "List the running LLM instances",
+	Long: `
+# List the LLMs in the default namespace.
+weave-ai ps
+
+# List the LLMs in all namespaces.
+weave-ai ps -A
+
+# List the names of the LLMs that are not ready.
+weave-ai ps -A -o go-template='{{range .items}}{{if ne .ready "True"}}{{.namespace}}/{{.name}}{{"\n"}}{{end}}{{end}}'
+
+# List the LLMs started with run --local on this machine.
+weave-ai ps --local
+`,
+	RunE: psCmdRun,
+}
+
+var psFlags struct {
+	namespace string
+	all       bool
+	local     bool
+	output    string
+}
+
+func init() {
+	psCmd.Flags().BoolVarP(&psFlags.all, "all-namespaces", "A", false, "lists the LLMs from all namespaces")
+	psCmd.Flags().BoolVar(&psFlags.local, "local", false, "lists the LLMs running on this machine")
+	psCmd.Flags().StringVarP(&psFlags.output, "output", "o", "", "output format, "+outputFlagUsage)
+
+	// TODO use the default namespace from context
+	psCmd.Flags().StringVarP(&psFlags.namespace, "namespace", "n", "default", "lists the LLMs in the specific namespace")
+	rootCmd.AddCommand(psCmd)
+}
+
+func psCmdRun(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(psFlags.output); err != nil {
+		return err
+	}
+
+	if psFlags.local {
+		return psCmdRunLocal()
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	namespace := psFlags.namespace
+	if psFlags.all {
+		namespace = ""
+	}
+
+	lms := &aiv1a1.LanguageModelList{}
+	if err := client.List(ctx, lms, runtimeclient.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	// the engine and the chat UI share the namespace of their LLM, so fetch
+	// them once instead of once per LLM, and only the ones of LLMs
+	deploymentsByName := map[types.NamespacedName]*appsv1.Deployment{}
+	for _, label := range []string{lmNameLabel, languageModelLabel} {
+		deployments := &appsv1.DeploymentList{}
+		if err := client.List(ctx, deployments, runtimeclient.InNamespace(namespace), runtimeclient.HasLabels{label}); err != nil {
+			return err
+		}
+		for i := range deployments.Items {
+			deploymentsByName[runtimeclient.ObjectKeyFromObject(&deployments.Items[i])] = &deployments.Items[i]
+		}
+	}
+
+	services := &corev1.ServiceList{}
+	if err := client.List(ctx, services, runtimeclient.InNamespace(namespace), runtimeclient.HasLabels{lmNameLabel}); err != nil {
+		return err
+	}
+	servicesByName := map[types.NamespacedName]*corev1.Service{}
+	for i := range services.Items {
+		servicesByName[runtimeclient.ObjectKeyFromObject(&services.Items[i])] = &services.Items[i]
+	}
+
+	var items []lmItem
+	for i := range lms.Items {
+		lm := lms.Items[i]
+		key := types.NamespacedName{Namespace: lm.Namespace, Name: lm.Name}
+		uiKey := types.NamespacedName{Namespace: lm.Namespace, Name: lm.Name + "-chat-app"}
+
+		ready, reason := getReadiness(lm)
+		item := lmItem{
+			Name:          lm.Name,
+			Namespace:     lm.Namespace,
+			Model:         getModel(lm),
+			Ready:         ready,
+			Reason:        reason,
+			Replicas:      getReplicas(lm, deploymentsByName[key]),
+			ServiceType:   getServiceType(lm),
+			URL:           engineURL(lm.Namespace, lm.Name),
+			ExternalIP:    getExternalAddress(servicesByName[key]),
+			Revision:      lm.Status.LastAppliedRevision,
+			LastReconcile: lastReconcileTime(lm.Status.ReconcileRequestStatus),
+			Created:       lm.CreationTimestamp.Time,
+		}
+		_, item.UI = deploymentsByName[uiKey]
+		// the Ready condition only moves when readiness flips, not on every reconcile
+		if cond := apimeta.FindStatusCondition(lm.Status.Conditions, fluxmeta.ReadyCondition); cond != nil && cond.Status == metav1.ConditionTrue {
+			item.ReadySince = &cond.LastTransitionTime.Time
+		}
+		items = append(items, item)
+	}
+
+	return printList(os.Stdout, psFlags.output, items, lmColumns, func(item lmItem) string {
+		return item.Namespace + "/" + item.Name
+	})
+}
+
+// lmItem is an LLM in the cluster as printed by ps.
+type lmItem struct {
+	Name          string     `json:"name"`
+	Namespace     string     `json:"namespace"`
+	Model         string     `json:"model"`
+	Ready         string     `json:"ready"`
+	Reason        string     `json:"reason,omitempty"`
+	Replicas      string     `json:"replicas"`
+	ServiceType   string     `json:"serviceType"`
+	URL           string     `json:"url"`
+	ExternalIP    string     `json:"externalIP"`
+	UI            bool       `json:"ui"`
+	Revision      string     `json:"revision,omitempty"`
+	LastReconcile *time.Time `json:"lastReconcile,omitempty"`
+	ReadySince    *time.Time `json:"readySince,omitempty"`
+	Created       time.Time  `json:"created"`
+}
+
+var lmColumns = []column[lmItem]{
+	{header: "NAME", value: func(i lmItem) string { return i.Namespace + "/" + i.Name }},
+	{header: "MODEL", value: func(i lmItem) string { return i.Model }},
+	{header: "READY", value: func(i lmItem) string { return i.Ready }},
+	{header: "REASON", value: func(i lmItem) string { return i.Reason }},
+	{header: "REPLICAS", value: func(i lmItem) string { return i.Replicas }},
+	{header: "TYPE", value: func(i lmItem) string { return i.ServiceType }},
+	{header: "URL", value: func(i lmItem) string { return i.URL }},
+	{header: "EXTERNAL-IP", value: func(i lmItem) string { return i.ExternalIP }},
+	{header: "UI", value: func(i lmItem) string {
+		if i.UI {
+			return "yes"
+		}
+		return "no"
+	}},
+	{header: "CREATED", value: func(i lmItem) string { return humanize.Time(i.Created) }},
+	{header: "REVISION", wide: true, value: func(i lmItem) string { return i.Revision }},
+	{header: "LAST-RECONCILE", wide: true, value: func(i lmItem) string { return formatTime(i.LastReconcile) }},
+	{header: "READY-SINCE", wide: true, value: func(i lmItem) string { return formatTime(i.ReadySince) }},
+}
+
+func psCmdRunLocal() error {
+	instances, err := listLocalInstances()
+	if err != nil {
+		return err
+	}
+
+	var items []localItem
+	for _, instance := range instances {
+		status := "Exited"
+		if instance.Running() {
+			status = "Running"
+		}
+		items = append(items, localItem{localInstance: instance, Status: status})
+	}
+
+	return printList(os.Stdout, psFlags.output, items, localColumns, func(item localItem) string {
+		return item.Name
+	})
+}
+
+// localItem is an LLM started with run --local as printed by ps --local.
+type localItem struct {
+	*localInstance
+	Status string `json:"status"`
+}
+
+var localColumns = []column[localItem]{
+	{header: "NAME", value: func(i localItem) string { return i.Name }},
+	{header: "MODEL", value: func(i localItem) string { return i.Model }},
+	{header: "STATUS", value: func(i localItem) string { return i.Status }},
+	{header: "PID", value: func(i localItem) string { return strconv.Itoa(i.PID) }},
+	{header: "THREADS", value: func(i localItem) string { return strconv.Itoa(i.Threads) }},
+	{header: "URL", value: func(i localItem) string { return i.URL() }},
+	{header: "CREATED", value: func(i localItem) string { return humanize.Time(i.Created) }},
+	{header: "MODEL-FILE", wide: true, value: func(i localItem) string { return i.ModelFile }},
+	{header: "LOG-FILE", wide: true, value: func(i localItem) string { return i.LogFile }},
+}
+
+// engineHost returns the in-cluster address of the engine Service
+// the lm-controller creates for a LanguageModel.
+func engineHost(namespace, name string) string {
+	return name + "." + namespace + ".svc." + rootArgs.clusterDomain + ":8000"
+}
+
+func engineURL(namespace, name string) string {
+	return "http://" + engineHost(namespace, name)
+}
+
+func getModel(lm aiv1a1.LanguageModel) string {
+	model, ok := modelOf(&lm)
+	if !ok {
+		return "<unknown>"
+	}
+	return model.String()
+}
+
+func getReadiness(lm aiv1a1.LanguageModel) (string, string) {
+	cond := apimeta.FindStatusCondition(lm.Status.Conditions, fluxmeta.ReadyCondition)
+	if cond == nil {
+		return "Unknown", ""
+	}
+	return string(cond.Status), cond.Reason
+}
+
+func getReplicas(lm aiv1a1.LanguageModel, deployment *appsv1.Deployment) string {
+	desired := int32(1)
+	if lm.Spec.Engine.Replicas != nil {
+		desired = *lm.Spec.Engine.Replicas
+	}
+	var ready int32
+	if deployment != nil {
+		ready = deployment.Status.ReadyReplicas
+	}
+	return fmt.Sprintf("%d/%d", ready, desired)
+}
+
+func getServiceType(lm aiv1a1.LanguageModel) string {
+	if lm.Spec.Engine.ServiceType == "" {
+		return string(corev1.ServiceTypeClusterIP)
+	}
+	return string(lm.Spec.Engine.ServiceType)
+}
+
+func getExternalAddress(svc *corev1.Service) string {
+	if svc == nil || svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return "<none>"
+	}
+
+	var addrs []string
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			addrs = append(addrs, ingress.IP)
+		} else if ingress.Hostname != "" {
+			addrs = append(addrs, ingress.Hostname)
+		}
+	}
+	if len(addrs) == 0 {
+		return "<pending>"
+	}
+	return strings.Join(addrs, ",")
+}
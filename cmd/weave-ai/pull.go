@@ -1,25 +1,214 @@
 package main
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/models"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+	"github.com/weave-ai/weave-ai/pkg/oci"
+)
 
 var pullCmd = &cobra.Command{
 	Use:   "pull",
 	Args:  cobra.ExactArgs(1),
 	Short: "Pull a model OCI",
-	Long: `Pull a model OCI.
-# Pull a model OCI.
-weave-ai pull flux-7b
+	Long: `Pull a model OCI into ~/.weave-ai/models/<name>/<tag>.
+# Pull a model OCI from the model catalog.
+weave-ai pull zephyr-7b-beta
 
 # Pull a model OCI.
 weave-ai pull ghcr.io/weave-ai/flux-7b:v0.1.0-q5km-gguf
+
+# Pull a model OCI from a local registry.
+weave-ai pull localhost:5000/models/flux-7b:v0.1.0-q5km-gguf
 `,
 	RunE: pullCmdRun,
 }
 
+var pullFlags struct {
+	insecure bool
+	force    bool
+}
+
 func init() {
-	// rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().BoolVar(&pullFlags.insecure, "insecure", false, "allows pulling from registries over plain HTTP")
+	pullCmd.Flags().BoolVarP(&pullFlags.force, "force", "f", false, "pulls the model again even if it is already in the local cache")
+	rootCmd.AddCommand(pullCmd)
 }
 
 func pullCmdRun(cmd *cobra.Command, args []string) error {
-	return nil
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	_, err := pullModel(ctx, args[0], pullFlags.force)
+	return err
+}
+
+// weaveAIDir returns a path inside ~/.weave-ai, where the CLI keeps its
+// local state.
+func weaveAIDir(elem ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{home, ".weave-ai"}, elem...)...), nil
+}
+
+type modelSource struct {
+	name string // name of the model in the local cache
+	tag  string
+	ref  string // OCI reference of the model artifact
+}
+
+// resolveModelSource turns a catalog model name, optionally prefixed with its
+// namespace, or an OCI reference into the artifact to pull.
+func resolveModelSource(model string) (*modelSource, error) {
+	model = strings.TrimPrefix(model, "oci://")
+
+	first, _, hasSlash := strings.Cut(model, "/")
+	isRef := hasSlash && (strings.ContainsAny(first, ".:") || first == "localhost")
+	if !isRef {
+		if hasSlash {
+			// namespace/name of a catalog model
+			_, model, _ = strings.Cut(model, "/")
+		}
+		entries, err := catalog.Load(models.FS)
+		if err != nil {
+			return nil, err
+		}
+		entry, ok := catalog.Find(entries, model)
+		if !ok {
+			return nil, fmt.Errorf("model %s not found in the model catalog", model)
+		}
+		src := &modelSource{name: entry.Name, tag: "latest", ref: catalog.Reference(entry)}
+		if r := entry.Spec.Reference; r != nil {
+			if r.Digest != "" {
+				src.tag = strings.ReplaceAll(r.Digest, ":", "-")
+			} else if r.Tag != "" {
+				src.tag = r.Tag
+			}
+		}
+		return src, nil
+	}
+
+	parsed, err := name.ParseReference(model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI reference %q: %w", model, err)
+	}
+	repo := parsed.Context().RepositoryStr()
+	src := &modelSource{
+		name: repo[strings.LastIndex(repo, "/")+1:],
+		tag:  parsed.Identifier(),
+		ref:  model,
+	}
+	if digest, ok := parsed.(name.Digest); ok {
+		src.tag = strings.ReplaceAll(digest.DigestStr(), ":", "-")
+	}
+	return src, nil
+}
+
+// pullModel downloads a model artifact into the local cache and returns
+// the directory holding the model files.
+func pullModel(ctx context.Context, model string, force bool) (string, error) {
+	src, err := resolveModelSource(model)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := weaveAIDir("models", src.name, src.tag)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err == nil && !force {
+		logger.Successf("model %s:%s is already pulled to %s", src.name, src.tag, dir)
+		return dir, nil
+	}
+
+	client := &oci.Client{Insecure: pullFlags.insecure}
+	ref, err := client.ParseReference(src.ref)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Actionf("pulling %s", ref)
+	manifestCtx, cancelFn := context.WithTimeout(ctx, rootArgs.timeout)
+	defer cancelFn()
+	manifest, digest, err := client.Manifest(manifestCtx, ref)
+	if err != nil {
+		return "", err
+	}
+	layer, err := oci.SelectLayer(manifest, oci.ContentMediaType)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a model artifact: %w", ref, err)
+	}
+	logger.Successf("resolved %s to %s", ref, digest)
+
+	blob, err := weaveAIDir("blobs", layer.Digest.Algorithm, layer.Digest.Hex)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(blob); err != nil {
+		logger.Waitingf("downloading %s (%s)", layer.Digest, humanize.Bytes(uint64(layer.Size)))
+		if err := client.DownloadBlob(ctx, ref.Context(), layer, blob, newProgressPrinter()); err != nil {
+			fmt.Fprintln(os.Stderr)
+			return "", err
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+	logger.Successf("verified %s", layer.Digest)
+
+	logger.Actionf("unpacking to %s", dir)
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", err
+	}
+	f, err := os.Open(blob)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := oci.Extract(f, tmp); err != nil {
+		return "", fmt.Errorf("unpacking %s failed: %w", layer.Digest, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+
+	// the unpacked files are all we need, don't keep the model twice
+	f.Close()
+	if err := os.Remove(blob); err != nil {
+		return "", err
+	}
+
+	logger.Successf("model %s:%s pulled to %s", src.name, src.tag, dir)
+	return dir, nil
+}
+
+// newProgressPrinter returns a download progress callback that rewrites a
+// single line on stderr at most a few times per second.
+func newProgressPrinter() func(done, total int64) {
+	var last time.Time
+	return func(done, total int64) {
+		if done < total && time.Since(last) < 500*time.Millisecond {
+			return
+		}
+		last = time.Now()
+		percent := int64(100)
+		if total > 0 {
+			percent = done * 100 / total
+		}
+		fmt.Fprintf(os.Stderr, "\r  %s / %s (%d%%)   ", humanize.Bytes(uint64(done)), humanize.Bytes(uint64(total)), percent)
+	}
 }
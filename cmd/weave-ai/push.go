@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/models"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+	"github.com/weave-ai/weave-ai/pkg/gguf"
+	"github.com/weave-ai/weave-ai/pkg/oci"
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push [path] [oci://repository:tag]",
+	Args:  cobra.ExactArgs(2),
+	Short: "Push a model file or directory as a model OCI",
+	Long: `Push a model file, or a directory of model files, as a model OCI in the Flux format.
+The family, context length and parameter count are read from the GGUF metadata unless given.
+The credentials of the registry are read from the docker config, log in with docker login first.
+
+# Push a GGUF file
+weave-ai push ./my-llama.Q4_K_M.gguf oci://ghcr.io/my-org/models/my-llama:v1.0.0-q4km-gguf --family llama --context-length 4k --parameters 7B
+
+# Push to a local registry
+weave-ai push ./my-llama.Q4_K_M.gguf oci://localhost:5000/models/my-llama:v1.0.0-q4km-gguf
+`,
+	RunE: pushCmdRun,
+}
+
+var pushFlags struct {
+	family        string
+	contextLength string
+	parameters    string
+	license       string
+	source        string
+	revision      string
+	insecure      bool
+}
+
+func init() {
+	pushCmd.Flags().StringVar(&pushFlags.family, "family", "", "family of the model, e.g. llama or mistral")
+	pushCmd.Flags().StringVar(&pushFlags.contextLength, "context-length", "", "context length of the model, e.g. 4096 or 32k")
+	pushCmd.Flags().StringVar(&pushFlags.parameters, "parameters", "", "number of parameters of the model, e.g. 7B")
+	pushCmd.Flags().StringVar(&pushFlags.license, "license", "", "license of the model")
+	pushCmd.Flags().StringVar(&pushFlags.source, "source", "", "URL the model comes from, e.g. its Hugging Face repository")
+	pushCmd.Flags().StringVar(&pushFlags.revision, "revision", "", "revision of the model at its source")
+	pushCmd.Flags().BoolVar(&pushFlags.insecure, "insecure", false, "allows pushing to registries over plain HTTP")
+	rootCmd.AddCommand(pushCmd)
+}
+
+func pushCmdRun(cmd *cobra.Command, args []string) error {
+	path, url := args[0], args[1]
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	client := &oci.Client{Insecure: pushFlags.insecure}
+	ref, err := client.ParseReference(url)
+	if err != nil {
+		return err
+	}
+	tag, ok := ref.(name.Tag)
+	if !ok {
+		return fmt.Errorf("%s has no tag, push to a tag such as %s:v1.0.0-q4km-gguf", url, ref.Context())
+	}
+
+	annotations, err := modelAnnotations(pushFlags.contextLength, pushFlags.parameters, pushFlags.license)
+	if err != nil {
+		return err
+	}
+	if pushFlags.family != "" {
+		annotations[catalog.FamilyLabel] = pushFlags.family
+	}
+	describeFromGGUF(path, annotations)
+	if pushFlags.source != "" {
+		annotations[oci.SourceAnnotation] = pushFlags.source
+	}
+	if pushFlags.revision != "" {
+		annotations[oci.RevisionAnnotation] = pushFlags.revision
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	logger.Generatef("packaging %s", path)
+	archive, err := os.CreateTemp("", "weave-ai-push-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	if err := oci.Archive(path, archive); err != nil {
+		archive.Close()
+		return fmt.Errorf("packaging %s failed: %w", path, err)
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	logger.Actionf("pushing %s", ref)
+	digest, err := client.Push(ctx, ref, archive.Name(), annotations, newProgressPrinter())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	logger.Successf("pushed %s@%s", ref, digest)
+
+	repo := ref.Context().RepositoryStr()
+	logger.Actionf("add it to the model catalog with: weave-ai add-model %s --url oci://%s --tag %s",
+		repo[strings.LastIndex(repo, "/")+1:], ref.Context(), tag.TagStr())
+	return nil
+}
+
+// describeFromGGUF fills in the family, context length and parameter count
+// the flags left out from the GGUF header of the model, when there is one.
+func describeFromGGUF(path string, annotations map[string]string) {
+	file := path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		if file, err = findModelFile(path); err != nil {
+			return
+		}
+	}
+	if !strings.HasSuffix(strings.ToLower(file), ".gguf") {
+		return
+	}
+	f, err := gguf.ReadFile(file)
+	if err != nil {
+		logger.Warningf("reading the GGUF metadata of %s failed: %v", file, err)
+		return
+	}
+
+	if _, ok := annotations[catalog.FamilyLabel]; !ok {
+		if family := guessFamily(f); family != "" {
+			annotations[catalog.FamilyLabel] = family
+		}
+	}
+	if _, ok := annotations[catalog.ContextLengthAnnotation]; !ok && f.ContextLength() > 0 {
+		annotations[catalog.ContextLengthAnnotation] = fmt.Sprint(f.ContextLength())
+	}
+	if _, ok := annotations[catalog.ParametersAnnotation]; !ok && f.ParameterCount() > 0 {
+		annotations[catalog.ParametersAnnotation] = catalog.FormatParameters(roundParameters(f.ParameterCount()))
+	}
+}
+
+// guessFamily names the family of a model the way the catalog does. The
+// architecture is only a last resort, since fine-tunes of Mistral, such as
+// Zephyr, report llama.
+func guessFamily(f *gguf.File) string {
+	var entries []catalog.IndexEntry
+	if index, err := catalog.ReadIndex(bytes.NewReader(models.Index)); err == nil {
+		entries = index.Models
+	}
+	for _, name := range []string{f.BaseName(), f.Name()} {
+		if family, ok := catalog.GuessFamily(entries, name); ok {
+			return family
+		}
+	}
+	if arch := f.Architecture(); arch != "" {
+		logger.Warningf("guessed the family %s from the architecture of the model, set it with --family if it is wrong", arch)
+		return arch
+	}
+	return ""
+}
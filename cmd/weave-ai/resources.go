@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// engineResourceFlags are the resources of the engine of an LLM, as given
+// to run and create-lm.
+type engineResourceFlags struct {
+	cpu              string
+	memory           string
+	cpuLimit         string
+	memoryLimit      string
+	ephemeralStorage string
+}
+
+func (f *engineResourceFlags) addFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&f.cpu, "cpu", "c", "4", "CPU requested by the engine of the LLM")
+	flags.StringVar(&f.memory, "memory", "", "memory requested by the engine of the LLM, e.g. 8Gi for a 7B model")
+	flags.StringVar(&f.cpuLimit, "cpu-limit", "", "maximum CPU of the engine of the LLM")
+	flags.StringVar(&f.memoryLimit, "memory-limit", "", "maximum memory of the engine of the LLM, above which it is OOMKilled")
+	flags.StringVar(&f.ephemeralStorage, "ephemeral-storage", "", "local storage requested by the engine of the LLM to hold the model, e.g. 10Gi")
+}
+
+// requirements validates the flags and returns them as the resources of
+// a container.
+func (f *engineResourceFlags) requirements() (corev1.ResourceRequirements, error) {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	for _, q := range []struct {
+		list  corev1.ResourceList
+		flag  string
+		value string
+		name  corev1.ResourceName
+	}{
+		{requests, "--cpu", f.cpu, corev1.ResourceCPU},
+		{requests, "--memory", f.memory, corev1.ResourceMemory},
+		{requests, "--ephemeral-storage", f.ephemeralStorage, corev1.ResourceEphemeralStorage},
+		{limits, "--cpu-limit", f.cpuLimit, corev1.ResourceCPU},
+		{limits, "--memory-limit", f.memoryLimit, corev1.ResourceMemory},
+	} {
+		if err := setQuantity(q.list, q.flag, q.value, q.name); err != nil {
+			return corev1.ResourceRequirements{}, err
+		}
+	}
+
+	if err := checkLimit(requests, limits, corev1.ResourceCPU, "--cpu"); err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+	if err := checkLimit(requests, limits, corev1.ResourceMemory, "--memory"); err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+
+	r := corev1.ResourceRequirements{}
+	if len(requests) > 0 {
+		r.Requests = requests
+	}
+	if len(limits) > 0 {
+		r.Limits = limits
+	}
+	return r, nil
+}
+
+// checkLimit fails when a limit is below its request, which the API server
+// would reject once the LLM is created.
+func checkLimit(requests, limits corev1.ResourceList, name corev1.ResourceName, flag string) error {
+	request, hasRequest := requests[name]
+	limit, hasLimit := limits[name]
+	if hasRequest && hasLimit && limit.Cmp(request) < 0 {
+		return fmt.Errorf("%s-limit %s is below %s %s", flag, limit.String(), flag, request.String())
+	}
+	return nil
+}
+
+// setQuantity parses the value of a flag, if set, into the given resources.
+func setQuantity(list corev1.ResourceList, flag, value string, names ...corev1.ResourceName) error {
+	if value == "" {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", flag, value, err)
+	}
+	if quantity.Sign() <= 0 {
+		return fmt.Errorf("invalid %s %q: must be positive", flag, value)
+	}
+	for _, name := range names {
+		list[name] = quantity
+	}
+	return nil
+}
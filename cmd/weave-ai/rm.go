@@ -1,8 +1,322 @@
 package main
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	aiv1a1 "github.com/weave-ai/lm-controller/api/v1alpha1"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
 
 var removeCmd = &cobra.Command{
-	Use:   "rm",
-	Short: "Remove an LLM",
+	Use:     "rm [name...]",
+	Aliases: []string{"remove"},
+	Short:   "Remove an LLM",
+	Long: `
+# Remove the LLM my-llm from the default namespace.
+weave-ai rm my-llm
+
+# Remove two LLMs from the dev-space namespace.
+weave-ai rm -n dev-space llm-a llm-b
+
+# Remove all LLMs in the default namespace without asking for confirmation.
+weave-ai rm --all --force
+
+# Remove all LLMs created from the zephyr-7b-beta model.
+weave-ai rm -l ai.contrib.fluxcd.io/model=zephyr-7b-beta
+
+# Remove my-llm and suspend its model if no other LLM uses it.
+weave-ai rm --suspend-model my-llm
+
+# Stop and remove the LLM my-llm started with run --local.
+weave-ai rm --local my-llm
+`,
+	RunE: removeCmdRun,
+}
+
+var removeFlags struct {
+	namespace    string
+	all          bool
+	selector     string
+	force        bool
+	suspendModel bool
+	local        bool
+}
+
+func init() {
+	removeCmd.Flags().BoolVar(&removeFlags.all, "all", false, "removes all LLMs in the namespace")
+	removeCmd.Flags().StringVarP(&removeFlags.selector, "selector", "l", "", "removes the LLMs matching the label selector")
+	removeCmd.Flags().BoolVarP(&removeFlags.force, "force", "f", false, "skips confirmation and removes the LLMs without waiting for the lm-controller to finalize them")
+	removeCmd.Flags().BoolVar(&removeFlags.suspendModel, "suspend-model", false, "suspends the models that are no longer used by any LLM without asking")
+	removeCmd.Flags().BoolVar(&removeFlags.local, "local", false, "removes LLMs running on this machine")
+
+	// TODO use the default namespace from context
+	removeCmd.Flags().StringVarP(&removeFlags.namespace, "namespace", "n", "default", "removes the LLMs from the specific namespace")
+	rootCmd.AddCommand(removeCmd)
+}
+
+func removeCmdRun(cmd *cobra.Command, args []string) error {
+	modes := 0
+	if len(args) > 0 {
+		modes++
+	}
+	if removeFlags.all {
+		modes++
+	}
+	if removeFlags.selector != "" {
+		modes++
+	}
+	if modes != 1 {
+		return fmt.Errorf("specify either LLM names, --all or --selector")
+	}
+
+	if removeFlags.local {
+		return removeCmdRunLocal(args)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	var lms []aiv1a1.LanguageModel
+	if len(args) > 0 {
+		for _, name := range args {
+			lm := aiv1a1.LanguageModel{}
+			key := types.NamespacedName{Namespace: removeFlags.namespace, Name: name}
+			if err := client.Get(ctx, key, &lm); err != nil {
+				if apierrors.IsNotFound(err) {
+					return fmt.Errorf("LLM %s/%s not found", removeFlags.namespace, name)
+				}
+				return err
+			}
+			lms = append(lms, lm)
+		}
+	} else {
+		opts := []runtimeclient.ListOption{runtimeclient.InNamespace(removeFlags.namespace)}
+		if removeFlags.selector != "" {
+			selector, err := labels.Parse(removeFlags.selector)
+			if err != nil {
+				return fmt.Errorf("invalid selector %q: %w", removeFlags.selector, err)
+			}
+			opts = append(opts, runtimeclient.MatchingLabelsSelector{Selector: selector})
+		}
+
+		list := &aiv1a1.LanguageModelList{}
+		if err := client.List(ctx, list, opts...); err != nil {
+			return err
+		}
+		lms = list.Items
+
+		if len(lms) == 0 {
+			logger.Successf("no LLMs found in %s namespace", removeFlags.namespace)
+			return nil
+		}
+
+		if !removeFlags.force {
+			var names []string
+			for _, lm := range lms {
+				names = append(names, lm.Name)
+			}
+			if !confirm("remove %d LLM(s) from %s namespace: %s", len(lms), removeFlags.namespace, strings.Join(names, ", ")) {
+				return fmt.Errorf("aborted")
+			}
+		}
+	}
+
+	// remember which models were in use before removing anything
+	models := map[types.NamespacedName]bool{}
+	for i := range lms {
+		if model, ok := modelOf(&lms[i]); ok {
+			models[model] = true
+		}
+	}
+
+	for i := range lms {
+		if err := removeLanguageModel(ctx, client, &lms[i], removeFlags.force); err != nil {
+			return err
+		}
+	}
+
+	for model := range models {
+		if err := offerModelSuspension(ctx, client, model); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeCmdRunLocal(names []string) error {
+	if removeFlags.selector != "" {
+		return fmt.Errorf("--selector is not supported with --local")
+	}
+
+	var instances []*localInstance
+	if removeFlags.all {
+		all, err := listLocalInstances()
+		if err != nil {
+			return err
+		}
+		if len(all) == 0 {
+			logger.Successf("no local LLMs found")
+			return nil
+		}
+		if !removeFlags.force && !confirm("remove %d local LLM(s)", len(all)) {
+			return fmt.Errorf("aborted")
+		}
+		instances = all
+	} else {
+		for _, name := range names {
+			instance, err := loadLocalInstance(name)
+			if err != nil {
+				return err
+			}
+			instances = append(instances, instance)
+		}
+	}
+
+	for _, instance := range instances {
+		if err := removeLocalInstance(instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// modelOf returns the catalog model a LanguageModel was created from,
+// preferring the labels set by run over the source reference.
+func modelOf(lm *aiv1a1.LanguageModel) (types.NamespacedName, bool) {
+	name := lm.Labels[modelLabel]
+	namespace := lm.Labels[modelNamespaceLabel]
+	if name == "" {
+		name = lm.Spec.SourceRef.Name
+		namespace = lm.Spec.SourceRef.Namespace
+	}
+	if name == "" {
+		return types.NamespacedName{}, false
+	}
+	if namespace == "" {
+		namespace = lm.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, true
+}
+
+func removeLanguageModel(ctx context.Context, client runtimeclient.Client, lm *aiv1a1.LanguageModel, force bool) error {
+	logger.Actionf("removing LLM %s/%s", lm.Namespace, lm.Name)
+
+	// the objects deployed by the lm-controller are recorded in the inventory,
+	// the chat UI is created by run next to them
+	owned, err := ownedObjects(lm)
+	if err != nil {
+		return err
+	}
+
+	if force && len(lm.Finalizers) > 0 {
+		patch := runtimeclient.MergeFrom(lm.DeepCopy())
+		lm.Finalizers = nil
+		if err := client.Patch(ctx, lm, patch); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if err := client.Delete(ctx, lm); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	// without the finalizer nobody prunes the engine objects, and chat UIs
+	// created by older versions may not carry an owner reference
+	for _, obj := range owned {
+		if !force && !strings.HasSuffix(obj.GetName(), "-chat-app") {
+			continue
+		}
+		if err := client.Delete(ctx, obj, runtimeclient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	logger.Waitingf("waiting for LLM %s/%s and its resources to be removed", lm.Namespace, lm.Name)
+	waitFor := append([]runtimeclient.Object{lm}, owned...)
+	w, err := newWaiter(client)
+	if err != nil {
+		return err
+	}
+	if err := w.WaitForDeletion(ctx, waitFor...); err != nil {
+		return fmt.Errorf("LLM %s/%s was not removed: %w", lm.Namespace, lm.Name, err)
+	}
+
+	logger.Successf("LLM %s/%s removed", lm.Namespace, lm.Name)
+	return nil
+}
+
+// ownedObjects returns the objects belonging to a LanguageModel: the inventory
+// of the lm-controller and the chat UI Deployment and Service created by run.
+func ownedObjects(lm *aiv1a1.LanguageModel) ([]runtimeclient.Object, error) {
+	var objs []runtimeclient.Object
+	if lm.Status.Inventory != nil {
+		for _, entry := range lm.Status.Inventory.Entries {
+			objMeta, err := object.ParseObjMetadata(entry.ID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid inventory entry %q of LLM %s/%s: %w", entry.ID, lm.Namespace, lm.Name, err)
+			}
+			u := &unstructured.Unstructured{}
+			u.SetGroupVersionKind(objMeta.GroupKind.WithVersion(entry.Version))
+			u.SetNamespace(objMeta.Namespace)
+			u.SetName(objMeta.Name)
+			objs = append(objs, u)
+		}
+	}
+
+	uiAppName := lm.Name + "-chat-app"
+	objs = append(objs,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: uiAppName, Namespace: lm.Namespace}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: uiAppName, Namespace: lm.Namespace}},
+	)
+	return objs, nil
+}
+
+// offerModelSuspension suspends a model again once no LanguageModel refers
+// to it anymore, so that it stops holding an artifact in the cluster.
+func offerModelSuspension(ctx context.Context, client runtimeclient.Client, model types.NamespacedName) error {
+	users, err := modelUsers(ctx, client, model)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return nil
+	}
+
+	repo := &sourcev1b2.OCIRepository{}
+	if err := client.Get(ctx, model, repo); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if repo.Spec.Suspend {
+		return nil
+	}
+
+	if !removeFlags.suspendModel {
+		if removeFlags.force || !confirm("model %s is no longer used by any LLM, suspend it", model) {
+			return nil
+		}
+	}
+
+	return suspendModel(ctx, client, repo)
 }
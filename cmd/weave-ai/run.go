@@ -3,17 +3,19 @@ package main
 import (
 	"context"
 	"fmt"
-	fluxmeta "github.com/fluxcd/pkg/apis/meta"
 	"io"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/client-go/kubernetes"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 
 	"github.com/weave-ai/weave-ai/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
-	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
-	"k8s.io/apimachinery/pkg/util/wait"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"strings"
 	"time"
@@ -46,6 +48,9 @@ weave-ai run -p -d weave-ai/zephyr-7b-beta
 # Run zephyr-7b-beta using 6 CPU units.
 weave-ai run -c 6 zephyr-7b-beta
 
+# Run zephyr-7b-beta with 8Gi of memory, capped at 12Gi, and 10Gi of local storage for the model.
+weave-ai run --memory 8Gi --memory-limit 12Gi --ephemeral-storage 10Gi zephyr-7b-beta
+
 # Run zephyr-7b-beta with 8 CPUs, detached, and named 'llm-test'.
 weave-ai run -c 8 -d --name=llm-test zephyr-7b-beta
 
@@ -55,6 +60,15 @@ weave-ai run -n dev-space zephyr-7b-beta
 # Deploys zephyr-7b-beta in 'prod-space', as a LoadBalancer service with UI.
 weave-ai run -n prod-space -p --ui zephyr-7b-beta
 
+# Deploys zephyr-7b-beta with UI and connects to both through port forwarding.
+weave-ai run --ui --connect zephyr-7b-beta
+
+# Prints the manifests of zephyr-7b-beta with UI, ready to be committed to a Flux repository.
+weave-ai run --export --ui --name=my-llm zephyr-7b-beta
+
+# Runs zephyr-7b-beta on this machine with 4 threads, detached.
+weave-ai run --local -d zephyr-7b-beta
+
 # Runs zephyr-7b-beta with 5 CPUs, in 'test-space', 
 # detached, named 'llm-prod', published as a service with UI.
 weave-ai run -c 5 -n test-space -d --name=llm-prod -p --ui zephyr-7b-beta
@@ -66,21 +80,25 @@ var runFlags struct {
 	namespace      string
 	name           string // name of the LLM
 	publish        bool   // publish the LLM, which means it will be exposed as a LoadBalancer service
-	cpu            string
+	resources      engineResourceFlags
 	modelName      string
 	modelNamespace string
 	detach         bool // detach from the process e.g. not follow the logs
 	ui             bool // start the UI
+	connect        bool // forward the LLM, and the UI, to local ports once ready
+	export         bool // print the manifests instead of creating them
 	local          bool // run the LLM locally
 }
 
 func init() {
-	runCmd.Flags().StringVarP(&runFlags.cpu, "cpu", "c", "4", "specifies CPU resources for the LLM")
+	runFlags.resources.addFlags(runCmd.Flags())
 	runCmd.Flags().BoolVarP(&runFlags.detach, "detach", "d", false, "detaches from the Pod session, allowing the LLM to run in the background without showing logs")
 	runCmd.Flags().StringVar(&runFlags.name, "name", "", "assigns a name to the LLM instance for identification")
 	runCmd.Flags().BoolVarP(&runFlags.publish, "publish", "p", false, "makes the LLM available as a network-accessible LoadBalancer service")
 	runCmd.Flags().BoolVar(&runFlags.ui, "ui", false, "starts the Weave Chat UI with the LLM for graphical interaction")
-	// runCmd.Flags().BoolVar(&runFlags.local, "local", false, "run the LLM locally")
+	runCmd.Flags().BoolVar(&runFlags.connect, "connect", false, "connects to the LLM, and the UI, through port forwarding once it is ready")
+	runCmd.Flags().BoolVar(&runFlags.export, "export", false, "prints the manifests of the LLM, and the UI, as YAML instead of creating them")
+	runCmd.Flags().BoolVar(&runFlags.local, "local", false, "runs the LLM on this machine from the local model cache instead of the cluster")
 
 	// TODO use the default namespace from context
 	runCmd.Flags().StringVarP(&runFlags.namespace, "namespace", "n", "default", "runs the LLM in the specific namespace")
@@ -95,26 +113,133 @@ func runCmdRun(cmd *cobra.Command, args []string) error {
 }
 
 func runCmdRunLocal(cmd *cobra.Command, args []string) error {
-	/*
-		modelName := args[0]
-		// if model name contains / split it into model namespace and model name
-		if strings.Contains(modelName, "/") {
-			split := strings.SplitN(modelName, "/", 2)
-			runFlags.modelNamespace = split[0]
-			runFlags.modelName = split[1]
-		} else {
-			runFlags.modelName = modelName
-			runFlags.modelNamespace = defaultNamespace
-		}
+	if runFlags.ui || runFlags.publish || runFlags.connect || runFlags.export {
+		return fmt.Errorf("--ui, --publish, --connect and --export are not supported with --local")
+	}
+	if r := runFlags.resources; r.memory != "" || r.cpuLimit != "" || r.memoryLimit != "" || r.ephemeralStorage != "" {
+		return fmt.Errorf("--memory, --cpu-limit, --memory-limit and --ephemeral-storage are not supported with --local")
+	}
 
-		// 1. check ~/.weave-ai/bin
-		// 2. download https://github.com/Mozilla-Ocho/llamafile/releases/download/0.4/llamafile-0.4
-		// 3. rename to llamafile
-		// 4. chmod +x llamafile
-		// 5. move to ~/.weave-ai/bin
-		// 6. run llamafile run
-	*/
-	return nil
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	lmName := runFlags.name
+	if lmName == "" {
+		// random name using the docker name lib
+		lmName = namesgenerator.GetRandomName(0)
+	}
+	if instance, err := loadLocalInstance(lmName); err == nil && instance.Running() {
+		return fmt.Errorf("local LLM %s is already running at %s", lmName, instance.URL())
+	}
+
+	cpu, err := resource.ParseQuantity(runFlags.resources.cpu)
+	if err != nil {
+		return fmt.Errorf("invalid --cpu value %q: %w", runFlags.resources.cpu, err)
+	}
+	threads := int(cpu.Value())
+
+	// 1. make sure the model is in the local cache
+	modelDir, err := pullModel(ctx, args[0], false)
+	if err != nil {
+		return err
+	}
+	modelFile, err := findModelFile(modelDir)
+	if err != nil {
+		return err
+	}
+
+	// 2. make sure llamafile is in ~/.weave-ai/bin
+	bin, err := ensureLlamafile(ctx)
+	if err != nil {
+		return err
+	}
+
+	port, err := freeLocalPort(enginePort)
+	if err != nil {
+		return err
+	}
+	if port != enginePort {
+		logger.Warningf("port %d is busy, using %d", enginePort, port)
+	}
+
+	logFile, err := weaveAIDir("run", lmName+".log")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logFile), 0o755); err != nil {
+		return err
+	}
+	logs, err := os.Create(logFile)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	// 3. start llamafile as an OpenAI-compatible server
+	logger.Actionf("starting local LLM %s with %d threads", lmName, threads)
+	server := exec.Command(bin,
+		"--server",
+		"--nobrowser",
+		"--host", "127.0.0.1",
+		"--port", strconv.Itoa(port),
+		"--threads", strconv.Itoa(threads),
+		"--model", modelFile,
+	)
+	server.Stdout = logs
+	server.Stderr = logs
+	if !runFlags.detach {
+		server.Stdout = io.MultiWriter(os.Stdout, logs)
+		server.Stderr = io.MultiWriter(os.Stderr, logs)
+	}
+	detachProcess(server)
+	if err := server.Start(); err != nil {
+		return fmt.Errorf("starting %s failed: %w", bin, err)
+	}
+	startTime, err := processStartTime(server.Process.Pid)
+	if err != nil {
+		server.Process.Kill()
+		return fmt.Errorf("reading the start time of %s failed: %w", bin, err)
+	}
+
+	instance := &localInstance{
+		Name:      lmName,
+		Model:     args[0],
+		ModelFile: modelFile,
+		PID:       server.Process.Pid,
+		StartTime: startTime,
+		Port:      port,
+		Threads:   threads,
+		LogFile:   logFile,
+		Created:   time.Now(),
+	}
+	if err := saveLocalInstance(instance); err != nil {
+		server.Process.Kill()
+		return err
+	}
+	logger.Successf("your LLM %s is starting at %s", lmName, instance.URL())
+
+	if runFlags.detach {
+		logger.Successf("logs are written to %s", logFile)
+		return server.Process.Release()
+	}
+
+	// stop the LLM together with the CLI when attached
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Wait()
+	}()
+	select {
+	case <-ctx.Done():
+		return removeLocalInstance(instance)
+	case err := <-done:
+		if rmErr := removeLocalInstance(instance); rmErr != nil {
+			return rmErr
+		}
+		if err != nil {
+			return fmt.Errorf("local LLM %s exited: %w", lmName, err)
+		}
+		return nil
+	}
 }
 
 func runCmdRun0(cmd *cobra.Command, args []string) error {
@@ -129,6 +254,11 @@ func runCmdRun0(cmd *cobra.Command, args []string) error {
 		runFlags.modelNamespace = defaultNamespace
 	}
 
+	resources, err := runFlags.resources.requirements()
+	if err != nil {
+		return err
+	}
+
 	lmName := runFlags.name
 	if lmName == "" {
 		// random name using the docker name lib
@@ -149,8 +279,8 @@ func runCmdRun0(cmd *cobra.Command, args []string) error {
 			Name:      lmName,
 			Namespace: runFlags.namespace,
 			Labels: map[string]string{
-				"ai.contrib.fluxcd.io/model-namespace": runFlags.modelNamespace,
-				"ai.contrib.fluxcd.io/model":           runFlags.modelName,
+				modelNamespaceLabel: runFlags.modelNamespace,
+				modelLabel:          runFlags.modelName,
 			},
 		},
 		Spec: aiv1a1.LanguageModelSpec{
@@ -166,19 +296,18 @@ func runCmdRun0(cmd *cobra.Command, args []string) error {
 			Engine: aiv1a1.EngineSpec{
 				ServiceType: corev1.ServiceType(serviceType),
 				Replicas:    &[]int32{1}[0],
-				Resources: corev1.ResourceRequirements{
-					Requests: corev1.ResourceList{
-						corev1.ResourceCPU: resource.MustParse(runFlags.cpu),
-					},
-				},
+				Resources:   resources,
 			},
 		},
 	}
 
-	if createLmFlags.export {
-		// TODO export manifests instead of installing
-		// fmt.Print(buffer.String())
-		return nil
+	if runFlags.export {
+		objs := []runtime.Object{lm}
+		if runFlags.ui {
+			ui, uiSvc := newChatApp(lm)
+			objs = append(objs, ui, uiSvc)
+		}
+		return exportObjects(os.Stdout, objs...)
 	}
 
 	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
@@ -198,44 +327,35 @@ func runCmdRun0(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	logger.Waitingf("waiting for %s/%s to be ready", runFlags.namespace, lmName)
-	waitCtx, waitCancel := context.WithCancel(ctx)
-	wait.UntilWithContext(waitCtx, func(ctx context.Context) {
-		if err := client.Get(ctx, runtimeclient.ObjectKeyFromObject(lm), lm); err != nil {
-			return
-		}
-		cond := apimeta.FindStatusCondition(lm.Status.Conditions, fluxmeta.ReadyCondition)
-		if cond == nil {
-			return
-		}
-		if cond.Status != metav1.ConditionTrue {
-			return
-		}
-		waitCancel()
-	}, 2*time.Second)
-
-	svc := &corev1.Service{}
-	if err := client.Get(ctx, runtimeclient.ObjectKeyFromObject(lm), svc); err != nil {
+	w, err := newWaiter(client)
+	if err != nil {
 		return err
 	}
 
+	logger.Waitingf("waiting for %s/%s to be ready", runFlags.namespace, lmName)
+	if err := w.WaitForReady(ctx, lm); err != nil {
+		return fmt.Errorf("LLM %s/%s is not ready: %w", runFlags.namespace, lmName, err)
+	}
+
 	if runFlags.publish {
+		// kstatus keeps a LoadBalancer Service in progress until it has an ingress
+		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: lm.Name, Namespace: lm.Namespace}}
 		logger.Waitingf("waiting for language model %s/%s to be published", runFlags.namespace, lmName)
-		waitCtx, waitCancel := context.WithCancel(ctx)
-		wait.UntilWithContext(waitCtx, func(ctx context.Context) {
-			if err := client.Get(ctx, runtimeclient.ObjectKeyFromObject(svc), svc); err != nil {
-				return
-			}
-			if len(svc.Status.LoadBalancer.Ingress) == 0 {
-				return
-			}
-			if svc.Status.LoadBalancer.Ingress[0].IP == "" {
-				return
-			}
-			waitCancel()
-		}, 2*time.Second)
-		ip := svc.Status.LoadBalancer.Ingress[0].IP
-		logger.Successf("your LLM is ready at http://%s:8000", ip)
+		if err := w.WaitForReady(ctx, svc); err != nil {
+			return fmt.Errorf("LLM %s/%s was not published: %w", runFlags.namespace, lmName, err)
+		}
+		if err := client.Get(ctx, runtimeclient.ObjectKeyFromObject(svc), svc); err != nil {
+			return err
+		}
+		if len(svc.Status.LoadBalancer.Ingress) == 0 {
+			return fmt.Errorf("service %s/%s has no load balancer ingress", svc.Namespace, svc.Name)
+		}
+		ingress := svc.Status.LoadBalancer.Ingress[0]
+		address := ingress.IP
+		if address == "" {
+			address = ingress.Hostname
+		}
+		logger.Successf("your LLM is ready at http://%s:8000", address)
 	}
 
 	var (
@@ -244,9 +364,63 @@ func runCmdRun0(cmd *cobra.Command, args []string) error {
 	)
 
 	if runFlags.ui {
-		uiAppName := lmName + "-chat-app"
-		clusterDomain := rootArgs.clusterDomain
-		ownerRefs := []metav1.OwnerReference{
+		ui, uiSvc = newChatApp(lm)
+		uiAppName := ui.Name
+		if err := client.Create(ctx, ui); err != nil {
+			return err
+		}
+
+		if err := client.Create(ctx, uiSvc); err != nil {
+			return err
+		}
+
+		// wait is good for the UI to be ready
+		logger.Waitingf("waiting for %s/%s to be ready", runFlags.namespace, uiAppName)
+		if err := w.WaitForReady(ctx, ui); err != nil {
+			return fmt.Errorf("chat UI %s/%s is not ready: %w", runFlags.namespace, uiAppName, err)
+		}
+	}
+
+	if runFlags.connect {
+		return connectLLM(runFlags.namespace, lm.Name, runFlags.ui, enginePort, uiPort)
+	}
+
+	if !runFlags.detach {
+		// follow the logs until interrupted, the timeout only covers the deployment
+		logCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if err := streamLogs(logCtx, logOptions{
+			namespace: runFlags.namespace,
+			name:      lm.Name,
+			ui:        runFlags.ui,
+			follow:    true,
+			tail:      -1,
+		}, os.Stdout); err != nil {
+			return err
+		}
+	} else {
+		// if detached, shows how to connect
+		if runFlags.ui {
+			logger.Successf("to connect to your LLM and the UI:\n  weave-ai connect -n %s --ui %s", lm.Namespace, lm.Name)
+		} else {
+			logger.Successf("to connect to your LLM:\n  weave-ai connect -n %s %s", lm.Namespace, lm.Name)
+		}
+	}
+
+	return nil
+}
+
+// newChatApp returns the Deployment and the Service of the Weave Chat UI
+// connected to the engine of a LanguageModel.
+func newChatApp(lm *aiv1a1.LanguageModel) (*appsv1.Deployment, *corev1.Service) {
+	uiAppName := lm.Name + "-chat-app"
+
+	// owner references need the UID of an existing LanguageModel, exported
+	// manifests are tied to it by the label only
+	var ownerRefs []metav1.OwnerReference
+	if lm.UID != "" {
+		ownerRefs = []metav1.OwnerReference{
 			{
 				APIVersion:         "ai.contrib.fluxcd.io/v1alpha1",
 				Kind:               "LanguageModel",
@@ -256,196 +430,92 @@ func runCmdRun0(cmd *cobra.Command, args []string) error {
 				Controller:         &[]bool{true}[0],
 			},
 		}
+	}
 
-		labels := map[string]string{"app": uiAppName}
-		ui = &appsv1.Deployment{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "apps/v1",
-				Kind:       "Deployment",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:            uiAppName,
-				Namespace:       runFlags.namespace,
-				Labels:          labels,
-				OwnerReferences: ownerRefs,
+	selector := map[string]string{"app": uiAppName}
+	labels := map[string]string{"app": uiAppName, languageModelLabel: lm.Name}
+	ui := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            uiAppName,
+			Namespace:       lm.Namespace,
+			Labels:          labels,
+			OwnerReferences: ownerRefs,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &[]int32{1}[0],
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
 			},
-			Spec: appsv1.DeploymentSpec{
-				Replicas: &[]int32{1}[0],
-				Selector: &metav1.LabelSelector{
-					MatchLabels: labels,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: selector,
 				},
-				Template: corev1.PodTemplateSpec{
-					ObjectMeta: metav1.ObjectMeta{
-						Labels: labels,
+				Spec: corev1.PodSpec{
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:    &[]int64{65532}[0],
+						RunAsNonRoot: &[]bool{true}[0],
 					},
-					Spec: corev1.PodSpec{
-						SecurityContext: &corev1.PodSecurityContext{
-							RunAsUser:    &[]int64{65532}[0],
-							RunAsNonRoot: &[]bool{true}[0],
-						},
-						Containers: []corev1.Container{
-							{
-								Name:  "chat-app",
-								Image: ImageChatInfo,
-								Env: []corev1.EnvVar{
-									{
-										Name:  "LLM_API_HOST",
-										Value: svc.Name + "." + runFlags.namespace + ".svc." + clusterDomain + ":8000",
-									},
+					Containers: []corev1.Container{
+						{
+							Name:  "chat-app",
+							Image: ImageChatInfo,
+							Env: []corev1.EnvVar{
+								{
+									Name:  "LLM_API_HOST",
+									Value: engineHost(lm.Namespace, lm.Name),
 								},
-								SecurityContext: &corev1.SecurityContext{
-									Privileged:   &[]bool{false}[0],
-									RunAsNonRoot: &[]bool{true}[0],
-									RunAsUser:    &[]int64{65532}[0],
-									Capabilities: &corev1.Capabilities{
-										Drop: []corev1.Capability{
-											"ALL",
-										},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged:   &[]bool{false}[0],
+								RunAsNonRoot: &[]bool{true}[0],
+								RunAsUser:    &[]int64{65532}[0],
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{
+										"ALL",
 									},
 								},
-								Ports: []corev1.ContainerPort{
-									{
-										ContainerPort: 8501,
-										Name:          "http",
-										Protocol:      corev1.ProtocolTCP,
-									},
+							},
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: 8501,
+									Name:          "http",
+									Protocol:      corev1.ProtocolTCP,
 								},
 							},
 						},
 					},
 				},
 			},
-		}
-		if err := client.Create(ctx, ui); err != nil {
-			return err
-		}
+		},
+	}
 
-		uiSvc = &corev1.Service{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "v1",
-				Kind:       "Service",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:            uiAppName,
-				Namespace:       runFlags.namespace,
-				Labels:          labels,
-				OwnerReferences: ownerRefs,
-			},
-			Spec: corev1.ServiceSpec{
-				Selector: labels,
-				Type:     corev1.ServiceTypeClusterIP,
-				Ports: []corev1.ServicePort{
-					{
-						Name:       "http",
-						Port:       8501,
-						TargetPort: intstr.FromInt32(8501),
-					},
+	uiSvc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            uiAppName,
+			Namespace:       lm.Namespace,
+			Labels:          labels,
+			OwnerReferences: ownerRefs,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+			Type:     corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       8501,
+					TargetPort: intstr.FromInt32(8501),
 				},
 			},
-		}
-
-		if err := client.Create(ctx, uiSvc); err != nil {
-			return err
-		}
-
-		// wait is good for the UI to be ready
-		logger.Waitingf("waiting for %s/%s to be ready", runFlags.namespace, uiAppName)
-		waitCtx, waitCancel := context.WithCancel(ctx)
-		wait.UntilWithContext(waitCtx, func(ctx context.Context) {
-			if err := client.Get(ctx, runtimeclient.ObjectKeyFromObject(ui), ui); err != nil {
-				return
-			}
-			var cond *appsv1.DeploymentCondition
-			for _, condition := range ui.Status.Conditions {
-				if condition.Type == appsv1.DeploymentAvailable {
-					cond = &condition
-					break
-				}
-			}
-			if cond == nil {
-				return
-			}
-			if cond.Status != corev1.ConditionTrue {
-				return
-			}
-			waitCancel()
-		}, 2*time.Second)
-
-	}
-
-	if !runFlags.detach {
-		pods := &corev1.PodList{}
-
-		// engine pods
-		matchingLabels := runtimeclient.MatchingLabels{"app": lm.Name}
-		podLogOpts := corev1.PodLogOptions{
-			Container: "engine",
-			Follow:    true,
-		}
-
-		// if UI is enabled, wait for the UI pod to be ready
-		if runFlags.ui {
-			matchingLabels["app"] = lm.Name + "-chat-app"
-			podLogOpts.Container = "chat-app"
-		}
-
-		if err := client.List(ctx, pods,
-			runtimeclient.InNamespace(runFlags.namespace),
-			matchingLabels,
-		); err != nil {
-			return err
-		}
-
-		podName := pods.Items[0].Name
-		config, err := utils.KubeConfig(kubeconfigArgs, kubeclientOptions)
-		if err != nil {
-			return err
-		}
-
-		clientSet, err := kubernetes.NewForConfig(config)
-		if err != nil {
-			return err
-		}
-		req := clientSet.CoreV1().Pods(runFlags.namespace).GetLogs(podName, &podLogOpts)
-
-		var podLogs io.ReadCloser
-		i := 0
-		const MaxRetry = 10
-		for {
-			var err error
-			podLogs, err = req.Stream(context.Background())
-			if err != nil {
-				time.Sleep(1 * time.Second)
-				i = i + 1
-				if i > MaxRetry {
-					return err
-				}
-			}
-			break
-		}
-		if podLogs == nil {
-			return fmt.Errorf("could not get logs for pod %s/%s", runFlags.namespace, podName)
-		}
-		defer podLogs.Close()
-
-		// Stream the pod logs
-		buf := make([]byte, 2000)
-		for {
-			w, err := podLogs.Read(buf)
-			if w > 0 {
-				fmt.Print(string(buf[:w]))
-			}
-			if err != nil {
-				break
-			}
-		}
-	} else {
-		// if detached, shows kubectl port-forward commands
-		logger.Successf("to connect to your LLM:\n  kubectl port-forward -n %s svc/%s 8000:8000", svc.Namespace, svc.Name)
-		if runFlags.ui {
-			logger.Successf("to connect to the UI:\n  kubectl port-forward -n %s svc/%s 8501:8501", uiSvc.Namespace, uiSvc.Name)
-		}
+		},
 	}
 
-	return nil
+	return ui, uiSvc
 }
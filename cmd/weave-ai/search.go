@@ -0,0 +1,68 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [filter|text...]",
+	Short: "Search models by family, context length, quantization, size or status",
+	Long: `Search the models installed in the cluster, or the model catalog with --catalog.
+
+Filters have the form <key><op><value>:
+  family=mistral    the model family
+  context>=32k      the context length, in tokens
+  quant=q5km        the quantization, q5km and Q5_K_M are the same
+  params<=7b        the number of parameters
+  license=mit       the license, as on the model card
+  status=active     active, inactive, not-ready or unknown, only in a cluster
+Numbers support =, !=, <, <=, > and >=, the other keys = and !=.
+Any other argument is matched against the model name.
+
+# Search the cluster for active Mistral models.
+weave-ai search family=mistral status=active -A
+
+# Search the catalog for small models with a long context.
+weave-ai search --catalog 'context>=32k' 'params<=7b'
+
+# Search the catalog for Zephyr models.
+weave-ai search --catalog zephyr
+`,
+	RunE: searchCmdRun,
+}
+
+var searchFlags struct {
+	all        bool
+	catalog    bool
+	catalogURL string
+	output     string
+}
+
+func init() {
+	searchCmd.Flags().BoolVarP(&searchFlags.all, "all", "A", false, "search models in all namespaces")
+	searchCmd.Flags().BoolVar(&searchFlags.catalog, "catalog", false, "search the catalog built into the CLI instead of the cluster")
+	searchCmd.Flags().StringVar(&searchFlags.catalogURL, "catalog-url", "", "search the catalog index at this URL instead of the cluster")
+	searchCmd.Flags().StringVarP(&searchFlags.output, "output", "o", "", "output format, "+outputFlagUsage)
+	rootCmd.AddCommand(searchCmd)
+}
+
+func searchCmdRun(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(searchFlags.output); err != nil {
+		return err
+	}
+	filter, err := catalog.ParseFilter(args...)
+	if err != nil {
+		return err
+	}
+
+	if searchFlags.catalog || searchFlags.catalogURL != "" {
+		return listCatalogModels(searchFlags.catalogURL, filter, searchFlags.output)
+	}
+
+	namespace := *kubeconfigArgs.Namespace
+	if searchFlags.all {
+		namespace = ""
+	}
+	return listClusterModels(namespace, filter, searchFlags.output)
+}
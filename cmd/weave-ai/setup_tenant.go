@@ -1 +1,349 @@
 package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fluxcd/pkg/ssa"
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var setupTenantCmd = &cobra.Command{
+	Use:   "setup-tenant [namespace]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Set up a namespace for a team to run LLMs in",
+	Long: `Create the namespace of a tenant and let the lm-controller deploy LLMs
+in it as the given ServiceAccount. The ServiceAccount can read the models of
+the allowed model namespaces. CPU and memory can be capped with a
+ResourceQuota for the namespace and a LimitRange for each container.
+
+Deleting a tenant removes what setup-tenant created but the namespace,
+which may still hold the LLMs and the data of the team.
+
+# Set up a tenant running LLMs as the default ServiceAccount
+weave-ai setup-tenant team-a
+
+# Set up a tenant with its own ServiceAccount and a quota of 8 CPUs and 32Gi of memory
+weave-ai setup-tenant team-a --service-account llm-deployer --quota-cpu 8 --quota-memory 32Gi
+
+# Cap each engine at 4 CPUs and 16Gi of memory
+weave-ai setup-tenant team-a --max-cpu 4 --max-memory 16Gi --default-cpu 1 --default-memory 4Gi
+
+# Allow the models of the catalog and of the team's own catalog
+weave-ai setup-tenant team-a --allowed-model-namespaces weave-ai,team-a-models
+
+# Export the manifests to commit them to a GitOps repository
+weave-ai setup-tenant team-a --export > team-a.yaml
+
+# Remove the tenant
+weave-ai setup-tenant team-a --delete
+`,
+	RunE: setupTenantCmdRun,
+}
+
+var setupTenantFlags struct {
+	serviceAccount         string
+	quotaCPU               string
+	quotaMemory            string
+	maxCPU                 string
+	maxMemory              string
+	defaultCPU             string
+	defaultMemory          string
+	allowedModelNamespaces []string
+	export                 bool
+	delete                 bool
+}
+
+func init() {
+	setupTenantCmd.Flags().StringVar(&setupTenantFlags.serviceAccount, "service-account", "default", "ServiceAccount the lm-controller deploys the LLMs of the tenant as")
+	setupTenantCmd.Flags().StringVar(&setupTenantFlags.quotaCPU, "quota-cpu", "", "CPU quota of the namespace, e.g. 8")
+	setupTenantCmd.Flags().StringVar(&setupTenantFlags.quotaMemory, "quota-memory", "", "memory quota of the namespace, e.g. 32Gi")
+	setupTenantCmd.Flags().StringVar(&setupTenantFlags.maxCPU, "max-cpu", "", "maximum CPU of a container, e.g. 4")
+	setupTenantCmd.Flags().StringVar(&setupTenantFlags.maxMemory, "max-memory", "", "maximum memory of a container, e.g. 16Gi")
+	setupTenantCmd.Flags().StringVar(&setupTenantFlags.defaultCPU, "default-cpu", "", "CPU of the containers that don't set any")
+	setupTenantCmd.Flags().StringVar(&setupTenantFlags.defaultMemory, "default-memory", "", "memory of the containers that don't set any")
+	setupTenantCmd.Flags().StringSliceVar(&setupTenantFlags.allowedModelNamespaces, "allowed-model-namespaces", []string{defaultNamespace}, "namespaces of the models the tenant can use")
+	setupTenantCmd.Flags().BoolVar(&setupTenantFlags.export, "export", false, "export manifests instead of applying them")
+	setupTenantCmd.Flags().BoolVar(&setupTenantFlags.delete, "delete", false, "delete the tenant instead of setting it up")
+	rootCmd.AddCommand(setupTenantCmd)
+}
+
+const (
+	tenantRoleName        = "lm-tenant-role"
+	tenantRoleBindingName = "lm-tenant-role-binding"
+	tenantModelReaderName = "lm-tenant-model-reader"
+	tenantQuotaName       = "lm-tenant-quota"
+	tenantLimitRangeName  = "lm-tenant-limits"
+)
+
+// tenantRoleRules let the lm-controller deploy the engine of an LLM as the
+// ServiceAccount of the tenant.
+var tenantRoleRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments", "replicasets"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"services", "persistentvolumeclaims"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{"serving.knative.dev"},
+		Resources: []string{"services"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+}
+
+func setupTenantCmdRun(cmd *cobra.Command, args []string) error {
+	namespace := args[0]
+	flags := setupTenantFlags
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, errs[0])
+	}
+	if flags.export && flags.delete {
+		return fmt.Errorf("--export and --delete are mutually exclusive")
+	}
+
+	objs, err := newTenant(namespace)
+	if err != nil {
+		return err
+	}
+
+	if flags.export {
+		return exportObjects(os.Stdout, objs...)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	if flags.delete {
+		return deleteTenant(ctx, namespace)
+	}
+
+	var manifest bytes.Buffer
+	if err := exportObjects(&manifest, objs...); err != nil {
+		return err
+	}
+
+	logger.Actionf("setting up tenant %s", namespace)
+	applyOutput, err := utils.Apply(ctx, kubeconfigArgs, kubeclientOptions, manifest.Bytes(), func(e ssa.ChangeSetEntry) bool {
+		return false
+	})
+	if err != nil {
+		return fmt.Errorf("setting up tenant %s failed: %w", namespace, err)
+	}
+	fmt.Fprintln(os.Stderr, applyOutput)
+
+	logger.Successf("tenant %s is ready, run LLMs in it with: weave-ai run -n %s", namespace, namespace)
+	return nil
+}
+
+// newTenant returns the objects of a tenant according to the flags.
+func newTenant(namespace string) ([]runtime.Object, error) {
+	flags := setupTenantFlags
+	labels := map[string]string{tenantLabel: namespace}
+
+	objs := []runtime.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: labels},
+		},
+	}
+	if flags.serviceAccount != "default" {
+		if errs := validation.IsDNS1123Subdomain(flags.serviceAccount); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid service account %q: %s", flags.serviceAccount, errs[0])
+		}
+		objs = append(objs, &corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: flags.serviceAccount, Namespace: namespace, Labels: labels},
+		})
+	}
+	objs = append(objs, newTenantRBAC(namespace, flags.serviceAccount, labels)...)
+
+	quota, err := newTenantQuota(namespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		objs = append(objs, quota)
+	}
+	limits, err := newTenantLimitRange(namespace, labels)
+	if err != nil {
+		return nil, err
+	}
+	if limits != nil {
+		objs = append(objs, limits)
+	}
+
+	subject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: flags.serviceAccount, Namespace: namespace}
+	for _, modelNamespace := range flags.allowedModelNamespaces {
+		if errs := validation.IsDNS1123Label(modelNamespace); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid model namespace %q: %s", modelNamespace, errs[0])
+		}
+		// one Role per tenant, so that deleting a tenant leaves the others alone
+		name := tenantModelReaderName + "-" + namespace
+		objs = append(objs,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: modelNamespace, Labels: labels},
+				Rules: []rbacv1.PolicyRule{{
+					APIGroups: []string{"source.toolkit.fluxcd.io"},
+					Resources: []string{"ocirepositories"},
+					Verbs:     []string{"get", "list", "watch"},
+				}},
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: modelNamespace, Labels: labels},
+				Subjects:   []rbacv1.Subject{subject},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+			},
+		)
+	}
+	return objs, nil
+}
+
+// newTenantRBAC returns the lm-tenant-role of a namespace and its binding
+// to a ServiceAccount. install sets up the default tenant with it too.
+func newTenantRBAC(namespace, serviceAccount string, labels map[string]string) []runtime.Object {
+	return []runtime.Object{
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: tenantRoleName, Namespace: namespace, Labels: labels},
+			Rules:      tenantRoleRules,
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: tenantRoleBindingName, Namespace: namespace, Labels: labels},
+			Subjects: []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount,
+				Namespace: namespace,
+			}},
+			RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: tenantRoleName},
+		},
+	}
+}
+
+func newTenantQuota(namespace string, labels map[string]string) (*corev1.ResourceQuota, error) {
+	hard := corev1.ResourceList{}
+	if err := setQuantity(hard, "--quota-cpu", setupTenantFlags.quotaCPU, corev1.ResourceRequestsCPU, corev1.ResourceLimitsCPU); err != nil {
+		return nil, err
+	}
+	if err := setQuantity(hard, "--quota-memory", setupTenantFlags.quotaMemory, corev1.ResourceRequestsMemory, corev1.ResourceLimitsMemory); err != nil {
+		return nil, err
+	}
+	if len(hard) == 0 {
+		return nil, nil
+	}
+	return &corev1.ResourceQuota{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ResourceQuota"},
+		ObjectMeta: metav1.ObjectMeta{Name: tenantQuotaName, Namespace: namespace, Labels: labels},
+		Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+	}, nil
+}
+
+func newTenantLimitRange(namespace string, labels map[string]string) (*corev1.LimitRange, error) {
+	item := corev1.LimitRangeItem{
+		Type:           corev1.LimitTypeContainer,
+		Max:            corev1.ResourceList{},
+		Default:        corev1.ResourceList{},
+		DefaultRequest: corev1.ResourceList{},
+	}
+	flags := setupTenantFlags
+	if err := setQuantity(item.Max, "--max-cpu", flags.maxCPU, corev1.ResourceCPU); err != nil {
+		return nil, err
+	}
+	if err := setQuantity(item.Max, "--max-memory", flags.maxMemory, corev1.ResourceMemory); err != nil {
+		return nil, err
+	}
+	if err := setQuantity(item.Default, "--default-cpu", flags.defaultCPU, corev1.ResourceCPU); err != nil {
+		return nil, err
+	}
+	if err := setQuantity(item.Default, "--default-memory", flags.defaultMemory, corev1.ResourceMemory); err != nil {
+		return nil, err
+	}
+	for name, quantity := range item.Default {
+		if max, ok := item.Max[name]; ok && quantity.Cmp(max) > 0 {
+			return nil, fmt.Errorf("the default %s %s is above the maximum %s", name, quantity.String(), max.String())
+		}
+		item.DefaultRequest[name] = quantity
+	}
+	if len(item.Max) == 0 && len(item.Default) == 0 {
+		return nil, nil
+	}
+	return &corev1.LimitRange{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "LimitRange"},
+		ObjectMeta: metav1.ObjectMeta{Name: tenantLimitRangeName, Namespace: namespace, Labels: labels},
+		Spec:       corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{item}},
+	}, nil
+}
+
+// deleteTenant removes the objects labelled with the tenant, in every
+// namespace, and keeps the namespace itself.
+func deleteTenant(ctx context.Context, namespace string) error {
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	ns := &corev1.Namespace{}
+	if err := client.Get(ctx, runtimeclient.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("tenant %s not found", namespace)
+		}
+		return err
+	}
+	if ns.Labels[tenantLabel] != namespace {
+		return fmt.Errorf("namespace %s wasn't set up with setup-tenant", namespace)
+	}
+
+	selector := runtimeclient.MatchingLabels{tenantLabel: namespace}
+	lists := []struct {
+		kind string
+		list runtimeclient.ObjectList
+	}{
+		{"RoleBinding", &rbacv1.RoleBindingList{}},
+		{"Role", &rbacv1.RoleList{}},
+		{"ResourceQuota", &corev1.ResourceQuotaList{}},
+		{"LimitRange", &corev1.LimitRangeList{}},
+		{"ServiceAccount", &corev1.ServiceAccountList{}},
+	}
+	for _, l := range lists {
+		if err := client.List(ctx, l.list, selector); err != nil {
+			return err
+		}
+		items, err := apimeta.ExtractList(l.list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj := item.(runtimeclient.Object)
+			if err := client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("deleting %s %s/%s failed: %w", l.kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+			logger.Actionf("%s/%s/%s deleted", l.kind, obj.GetNamespace(), obj.GetName())
+		}
+	}
+
+	delete(ns.Labels, tenantLabel)
+	if err := client.Update(ctx, ns); err != nil {
+		return err
+	}
+
+	logger.Successf("tenant %s deleted, the namespace is kept with its LLMs", namespace)
+	return nil
+}
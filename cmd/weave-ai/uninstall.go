@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fluxcd/pkg/ssa"
+	"github.com/spf13/cobra"
+	aiv1a1 "github.com/weave-ai/lm-controller/api/v1alpha1"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Args:  cobra.NoArgs,
+	Short: "Uninstall the Weave AI controllers",
+	Long: `Remove what install applied, in reverse order. The LLMs of all namespaces
+are removed first, while the lm-controller still runs their finalizers.
+The CRDs are kept unless --purge is given.
+
+# Uninstall the Weave AI controllers
+weave-ai uninstall
+
+# List what would be deleted
+weave-ai uninstall --dry-run
+
+# Uninstall without asking for confirmation, the CRDs included
+weave-ai uninstall --purge --silent
+`,
+	RunE: uninstallCmdRun,
+}
+
+var uninstallFlags struct {
+	version           string
+	fromBundle        string
+	withModelCatalog  bool
+	withDefaultTenant bool
+	defaultTenantNs   string
+	purge             bool
+	dryRun            bool
+	silent            bool
+}
+
+func init() {
+	uninstallCmd.Flags().StringVarP(&uninstallFlags.version, "version", "v", Version, "version of Weave AI that was installed")
+	uninstallCmd.Flags().StringVar(&uninstallFlags.fromBundle, "from-bundle", "", "bundle that Weave AI was installed from, a directory or a tar archive")
+	uninstallCmd.Flags().BoolVar(&uninstallFlags.withModelCatalog, "with-model-catalog", true, "uninstall the model catalog")
+	uninstallCmd.Flags().BoolVar(&uninstallFlags.withDefaultTenant, "with-default-tenant", true, "uninstall the default tenant")
+	uninstallCmd.Flags().StringVar(&uninstallFlags.defaultTenantNs, "default-tenant-namespace", "default", "namespace of the default tenant")
+	uninstallCmd.Flags().BoolVar(&uninstallFlags.purge, "purge", false, "delete the CRDs too")
+	uninstallCmd.Flags().BoolVar(&uninstallFlags.dryRun, "dry-run", false, "only list what would be deleted")
+	uninstallCmd.Flags().BoolVarP(&uninstallFlags.silent, "silent", "s", false, "delete without asking for confirmation")
+
+	rootCmd.AddCommand(uninstallCmd)
+}
+
+// namespaces that uninstall never deletes, even when install was pointed at them
+var protectedNamespaces = map[string]bool{
+	"default":         true,
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+func uninstallCmdRun(cmd *cobra.Command, args []string) error {
+	flags := uninstallFlags
+
+	logger.Generatef("generating manifests")
+	manifests, err := buildInstallManifests(flags.version, flags.fromBundle, flags.withModelCatalog, flags.withDefaultTenant, flags.defaultTenantNs)
+	if err != nil {
+		return err
+	}
+	objs, err := ssa.ReadObjects(strings.NewReader(string(manifests)))
+	if err != nil {
+		return err
+	}
+	logger.Successf("manifests build completed")
+
+	// delete in the reverse order of install: controllers, RBAC, CRDs and the namespace last
+	var toDelete []*unstructured.Unstructured
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		switch {
+		case obj.GetKind() == "CustomResourceDefinition" && !flags.purge:
+			continue
+		case obj.GetKind() == "Namespace" && protectedNamespaces[obj.GetName()]:
+			continue
+		}
+		toDelete = append(toDelete, obj)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	// no match for the kind means that the CRDs are already gone
+	lms := &aiv1a1.LanguageModelList{}
+	if err := client.List(ctx, lms); err != nil && !apimeta.IsNoMatchError(err) {
+		return err
+	}
+
+	if flags.dryRun {
+		for _, lm := range lms.Items {
+			fmt.Printf("%s/%s/%s\n", aiv1a1.LanguageModelKind, lm.Namespace, lm.Name)
+		}
+		for _, obj := range toDelete {
+			fmt.Println(ssa.FmtUnstructured(obj))
+		}
+		return nil
+	}
+
+	if !flags.silent {
+		what := "Weave AI"
+		if len(lms.Items) > 0 {
+			what = fmt.Sprintf("Weave AI and %d LLM(s)", len(lms.Items))
+		}
+		if !confirm("uninstall %s from the cluster", what) {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	for i := range lms.Items {
+		if err := removeLanguageModel(ctx, client, &lms.Items[i], false); err != nil {
+			return fmt.Errorf("uninstall failed: %w", err)
+		}
+	}
+
+	var deleted []runtimeclient.Object
+	for _, obj := range toDelete {
+		err := client.Delete(ctx, obj, runtimeclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		switch {
+		case apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err):
+			continue
+		case err != nil:
+			return fmt.Errorf("uninstall failed: deleting %s: %w", ssa.FmtUnstructured(obj), err)
+		}
+		logger.Actionf("%s deleted", ssa.FmtUnstructured(obj))
+		deleted = append(deleted, obj)
+	}
+
+	logger.Waitingf("waiting for the resources to be removed")
+	w, err := newWaiter(client)
+	if err != nil {
+		return err
+	}
+	if err := w.WaitForDeletion(ctx, deleted...); err != nil {
+		return fmt.Errorf("uninstall failed: %w", err)
+	}
+
+	logger.Successf("uninstall finished")
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fluxcd/pkg/ssa"
+	"github.com/spf13/cobra"
+	"github.com/weave-ai/weave-ai/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Args:  cobra.NoArgs,
+	Short: "Upgrade the Weave AI controllers",
+	Long: `Upgrade the Weave AI controllers to the lm-controller release matching a
+version of the CLI. The changes are shown before they are applied.
+
+# Upgrade to the version of this CLI
+weave-ai upgrade
+
+# Upgrade to a given release
+weave-ai upgrade --version 0.12.0
+
+# Only show what would change
+weave-ai upgrade --dry-run
+
+# Upgrade a cluster without network access from a bundle
+weave-ai upgrade --from-bundle ./weave-ai-bundle.tar
+`,
+	RunE: upgradeCmdRun,
+}
+
+var upgradeFlags struct {
+	version           string
+	fromBundle        string
+	withModelCatalog  bool
+	withDefaultTenant bool
+	defaultTenantNs   string
+	dryRun            bool
+}
+
+func init() {
+	upgradeCmd.Flags().StringVarP(&upgradeFlags.version, "version", "v", Version, "version of Weave AI to upgrade to")
+	upgradeCmd.Flags().StringVar(&upgradeFlags.fromBundle, "from-bundle", "", "upgrade from the manifests of a bundle, a directory or a tar archive, without network access")
+	upgradeCmd.Flags().BoolVar(&upgradeFlags.withModelCatalog, "with-model-catalog", true, "upgrade the model catalog")
+	upgradeCmd.Flags().BoolVar(&upgradeFlags.withDefaultTenant, "with-default-tenant", true, "upgrade the default tenant")
+	upgradeCmd.Flags().StringVar(&upgradeFlags.defaultTenantNs, "default-tenant-namespace", "default", "namespace of the default tenant")
+	upgradeCmd.Flags().BoolVar(&upgradeFlags.dryRun, "dry-run", false, "only show what would change")
+
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+func upgradeCmdRun(cmd *cobra.Command, args []string) error {
+	flags := upgradeFlags
+	// a version asked for must be known, the one of the CLI falls back to
+	// the latest lm-controller when building the manifests
+	controllerVersion, _ := lookupLMControllerVersion(flags.version)
+	if cmd.Flags().Changed("version") {
+		var err error
+		if controllerVersion, err = resolveLMControllerVersion(flags.version); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancelFn()
+
+	client, err := utils.KubeClient(kubeconfigArgs, kubeclientOptions)
+	if err != nil {
+		return err
+	}
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Namespace: *kubeconfigArgs.Namespace, Name: "lm-controller"}
+	if err := client.Get(ctx, key, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("lm-controller not found in %s namespace, run weave-ai install first", key.Namespace)
+		}
+		return err
+	}
+	current := "unknown"
+	if tag := imageTag(deployment, "lm-controller"); tag != "" {
+		current = tag
+	}
+	logger.Actionf("upgrading lm-controller from %s to %s", current, controllerVersion)
+
+	logger.Generatef("generating manifests")
+	manifests, err := buildInstallManifests(flags.version, flags.fromBundle, flags.withModelCatalog, flags.withDefaultTenant, flags.defaultTenantNs)
+	if err != nil {
+		return err
+	}
+	if flags.withModelCatalog {
+		// upgrading must not suspend the models activated since
+		manifests, err = keepActivatedModels(ctx, manifests, false)
+		if err != nil {
+			return err
+		}
+	}
+	logger.Successf("manifests build completed")
+
+	logger.Actionf("comparing with the cluster")
+	diffs, err := utils.Diff(ctx, kubeconfigArgs, kubeclientOptions, manifests)
+	if err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+	if len(diffs) == 0 {
+		logger.Successf("Weave AI is up to date")
+		return nil
+	}
+	printDiffs(diffs)
+
+	if flags.dryRun {
+		return nil
+	}
+
+	logger.Actionf("applying the changes")
+	applyOutput, err := utils.Apply(ctx, kubeconfigArgs, kubeclientOptions, manifests, func(e ssa.ChangeSetEntry) bool {
+		// suspended models never become ready
+		return e.ObjMetadata.GroupKind.Kind != "OCIRepository"
+	})
+	if err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, applyOutput)
+
+	return verifyTheInstallation()
+}
+
+// printDiffs prints the changes to stdout, one object per block, e.g.
+//
+//	~ Deployment/weave-ai/lm-controller configured
+//	    spec.template.spec.containers[0].image: ghcr.io/weave-ai/lm-controller:v0.9.0 -> ghcr.io/weave-ai/lm-controller:v0.10.0
+func printDiffs(diffs []utils.ObjectDiff) {
+	for _, diff := range diffs {
+		switch diff.Entry.Action {
+		case ssa.CreatedAction:
+			fmt.Printf("+ %s created\n", diff.Entry.Subject)
+		default:
+			fmt.Printf("~ %s %s\n", diff.Entry.Subject, diff.Entry.Action)
+		}
+		for _, c := range diff.Changes {
+			switch {
+			case c.Added:
+				fmt.Printf("    %s: + %s\n", c.Path, c.New)
+			case c.Removed:
+				fmt.Printf("    %s: - %s\n", c.Path, c.Old)
+			default:
+				fmt.Printf("    %s: %s -> %s\n", c.Path, c.Old, c.New)
+			}
+		}
+	}
+}
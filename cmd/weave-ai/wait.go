@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/weave-ai/weave-ai/pkg/waiter"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newWaiter returns a waiter reporting its progress through the logger.
+// It gives up when the context of the command, bound by --timeout, is done.
+func newWaiter(client runtimeclient.Client) (*waiter.Waiter, error) {
+	mapper, err := kubeconfigArgs.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return waiter.New(client, mapper, rootArgs.pollInterval, logger), nil
+}
@@ -0,0 +1,40 @@
+// Command catalog-index generates models/_index/index.json from the
+// OCIRepository of every catalog model. Run it with go generate ./models.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/weave-ai/weave-ai/pkg/catalog"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the model catalog")
+	out := flag.String("o", "_index/index.json", "file to write the index to")
+	flag.Parse()
+
+	if err := run(*dir, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "catalog-index: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, out string) error {
+	models, err := catalog.Load(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+	index, err := catalog.BuildIndex(models)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := catalog.WriteIndex(&buf, index); err != nil {
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0o644)
+}
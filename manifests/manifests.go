@@ -0,0 +1,12 @@
+// Package manifests embeds the release manifests of the lm-controller, so
+// that the CLI can install it without network access.
+package manifests
+
+import "embed"
+
+// LMController holds the manifests of the lm-controller releases, as
+// lm-controller/<version>/lm-controller.{crds,rbac,deployment}.yaml. They
+// are downloaded with make manifests before building a release.
+//
+//go:embed all:lm-controller
+var LMController embed.FS
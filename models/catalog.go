@@ -0,0 +1,17 @@
+// Package models embeds the model catalog, so that the CLI can resolve
+// catalog models without a cluster.
+package models
+
+import "embed"
+
+//go:generate go run ../hack/catalog-index -o _index/index.json
+
+// FS holds the kustomization and the OCIRepository of every catalog model.
+//
+//go:embed kustomization.yaml */ocirepo.yaml
+var FS embed.FS
+
+// Index is the machine-readable catalog generated from the models in FS.
+//
+//go:embed _index/index.json
+var Index []byte
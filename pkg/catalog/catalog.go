@@ -0,0 +1,71 @@
+// Package catalog reads the model catalog, a directory holding one
+// OCIRepository per model in <model>/ocirepo.yaml.
+package catalog
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"sigs.k8s.io/yaml"
+)
+
+// Load reads the OCIRepository of every model in the catalog, sorted by name.
+func Load(fsys fs.FS) ([]sourcev1b2.OCIRepository, error) {
+	files, err := fs.Glob(fsys, "*/ocirepo.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	var models []sourcev1b2.OCIRepository
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		model := sourcev1b2.OCIRepository{}
+		if err := yaml.Unmarshal(data, &model); err != nil {
+			return nil, fmt.Errorf("invalid catalog entry %s: %w", file, err)
+		}
+		if model.Kind != sourcev1b2.OCIRepositoryKind {
+			return nil, fmt.Errorf("invalid catalog entry %s: expected kind %s, got %q", file, sourcev1b2.OCIRepositoryKind, model.Kind)
+		}
+		if model.Name == "" {
+			model.Name = path.Dir(file)
+		}
+		models = append(models, model)
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Name < models[j].Name
+	})
+	return models, nil
+}
+
+// Find returns the catalog model with the given name.
+func Find(models []sourcev1b2.OCIRepository, name string) (*sourcev1b2.OCIRepository, bool) {
+	for i := range models {
+		if models[i].Name == name {
+			return &models[i], true
+		}
+	}
+	return nil, false
+}
+
+// Reference returns the OCI reference of a model, e.g.
+// ghcr.io/weave-ai/models/zephyr-7b-beta-8k:v1.0.0-q5km-gguf
+func Reference(model *sourcev1b2.OCIRepository) string {
+	ref := strings.TrimPrefix(model.Spec.URL, sourcev1b2.OCIRepositoryPrefix)
+	if model.Spec.Reference != nil {
+		switch {
+		case model.Spec.Reference.Digest != "":
+			return ref + "@" + model.Spec.Reference.Digest
+		case model.Spec.Reference.Tag != "":
+			return ref + ":" + model.Spec.Reference.Tag
+		}
+	}
+	return ref + ":latest"
+}
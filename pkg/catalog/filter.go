@@ -0,0 +1,167 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter selects models by structured conditions, e.g. family=mistral,
+// context>=32k, quant=q5km, params<=7b or status=active, and by free-text
+// terms matched against the model name. A model must satisfy all of them.
+type Filter struct {
+	conditions []condition
+	terms      []string
+}
+
+type condition struct {
+	key   string
+	op    string
+	value string
+	num   int64
+}
+
+// the longer operators come first so that >= isn't taken for >
+var filterOperators = []string{">=", "<=", "!=", "=", ">", "<"}
+
+var numericFilterKeys = map[string]bool{"context": true, "params": true}
+
+var filterKeyAliases = map[string]string{
+	"family":       "family",
+	"context":      "context",
+	"ctx":          "context",
+	"quant":        "quant",
+	"quantization": "quant",
+	"params":       "params",
+	"parameters":   "params",
+	"status":       "status",
+	"license":      "license",
+}
+
+// ParseFilter parses filter expressions. Arguments without an operator are
+// free-text terms.
+func ParseFilter(args ...string) (*Filter, error) {
+	f := &Filter{}
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+
+		var op string
+		idx := -1
+		for _, o := range filterOperators {
+			if i := strings.Index(arg, o); i > 0 && (idx < 0 || i < idx) {
+				idx, op = i, o
+			}
+		}
+		if idx < 0 {
+			f.terms = append(f.terms, strings.ToLower(arg))
+			continue
+		}
+
+		rawKey := strings.ToLower(strings.TrimSpace(arg[:idx]))
+		key, ok := filterKeyAliases[rawKey]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q, expected one of family, context, quant, params, status, license", rawKey)
+		}
+		c := condition{key: key, op: op, value: strings.TrimSpace(arg[idx+len(op):])}
+		if c.value == "" {
+			return nil, fmt.Errorf("filter %q has no value", arg)
+		}
+
+		switch {
+		case numericFilterKeys[key]:
+			var err error
+			if key == "context" {
+				c.num, err = ParseContextLength(c.value)
+			} else {
+				c.num, err = ParseParameters(c.value)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("filter %q: %w", arg, err)
+			}
+		case op != "=" && op != "!=":
+			return nil, fmt.Errorf("filter %q: %s only supports = and !=", arg, key)
+		case key == "quant":
+			if len(strings.ReplaceAll(c.value, "_", "")) < 2 {
+				return nil, fmt.Errorf("filter %q: invalid quantization %q, expected e.g. q5km or Q5_K_M", arg, c.value)
+			}
+			c.value = formatQuantization(strings.ToLower(c.value))
+		}
+		f.conditions = append(f.conditions, c)
+	}
+	return f, nil
+}
+
+// Uses reports whether the filter has a condition on key.
+func (f *Filter) Uses(key string) bool {
+	for _, c := range f.conditions {
+		if c.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether a model satisfies the filter. status is the state of
+// the model in a cluster, e.g. active, and is empty for catalog models.
+func (f *Filter) Match(entry IndexEntry, status string) bool {
+	name := strings.ToLower(entry.Name)
+	for _, term := range f.terms {
+		if !strings.Contains(name, term) {
+			return false
+		}
+	}
+
+	for _, c := range f.conditions {
+		var ok bool
+		switch c.key {
+		case "family":
+			ok = c.matchString(entry.Family)
+		case "quant":
+			ok = c.matchString(entry.Quantization)
+		case "license":
+			ok = c.matchString(entry.License)
+		case "status":
+			ok = c.matchString(status)
+		case "context":
+			ok = c.matchNumber(entry.ContextLength)
+		case "params":
+			ok = c.matchNumber(entry.Parameters)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) matchString(s string) bool {
+	equal := strings.EqualFold(s, c.value)
+	if c.op == "!=" {
+		return !equal
+	}
+	return equal
+}
+
+func (c condition) matchNumber(n int64) bool {
+	if n <= 0 {
+		// unknown values never match
+		return false
+	}
+	switch c.op {
+	case "=":
+		return n == c.num
+	case "!=":
+		return n != c.num
+	case ">=":
+		return n >= c.num
+	case "<=":
+		return n <= c.num
+	case ">":
+		return n > c.num
+	case "<":
+		return n < c.num
+	}
+	return false
+}
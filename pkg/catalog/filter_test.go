@@ -0,0 +1,42 @@
+package catalog
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	zephyr := IndexEntry{Name: "zephyr-7b-beta", Family: "mistral", Quantization: "Q5_K_M", ContextLength: 8192, Parameters: 7_000_000_000}
+	mixtral := IndexEntry{Name: "mixtral-8x7b-instruct", Family: "mixtral", Quantization: "Q5_K_M", ContextLength: 32768, Parameters: 46_700_000_000}
+
+	tests := []struct {
+		args            []string
+		status          string
+		zephyr, mixtral bool
+	}{
+		{[]string{"family=mistral"}, "", true, false},
+		{[]string{"context>=32k"}, "", false, true},
+		{[]string{"quant=q5km", "params<=7b"}, "", true, false},
+		{[]string{"family!=mistral", "instruct"}, "", false, true},
+		{[]string{"zephyr"}, "", true, false},
+		{[]string{"status=active"}, "active", true, true},
+		{[]string{"status=active"}, "inactive", false, false},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.args...)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", tt.args, err)
+		}
+		if got := f.Match(zephyr, tt.status); got != tt.zephyr {
+			t.Fatalf("%q matching %s = %v, expected %v", tt.args, zephyr.Name, got, tt.zephyr)
+		}
+		if got := f.Match(mixtral, tt.status); got != tt.mixtral {
+			t.Fatalf("%q matching %s = %v, expected %v", tt.args, mixtral.Name, got, tt.mixtral)
+		}
+	}
+}
+
+func TestParseFilterRejectsInvalidFilters(t *testing.T) {
+	for _, arg := range []string{"size=7b", "family>=mistral", "context>=lots", "params=", "quant=q", "quant=_q"} {
+		if _, err := ParseFilter(arg); err == nil {
+			t.Fatalf("expected an error for %q", arg)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+)
+
+// Labels and annotations describing a catalog model. The family is a label,
+// so that models can be selected by family in a cluster.
+const (
+	FamilyLabel             = "ai.contrib.fluxcd.io/family"
+	ContextLengthAnnotation = "ai.contrib.fluxcd.io/context-length"
+	ParametersAnnotation    = "ai.contrib.fluxcd.io/parameters"
+	LicenseAnnotation       = "ai.contrib.fluxcd.io/license"
+)
+
+// IndexVersion is the version of the index format written by BuildIndex.
+const IndexVersion = "v1"
+
+// Index is the machine-readable form of the model catalog, stored in
+// models/_index/index.json.
+type Index struct {
+	Version string       `json:"version"`
+	Models  []IndexEntry `json:"models"`
+}
+
+// IndexEntry describes one model of the catalog.
+type IndexEntry struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	Tag           string `json:"tag,omitempty"`
+	Digest        string `json:"digest,omitempty"`
+	Version       string `json:"version,omitempty"`
+	Family        string `json:"family,omitempty"`
+	Quantization  string `json:"quantization,omitempty"`
+	Format        string `json:"format,omitempty"`
+	ContextLength int64  `json:"contextLength,omitempty"`
+	Parameters    int64  `json:"parameters,omitempty"`
+	License       string `json:"license,omitempty"`
+}
+
+// BuildIndex turns the OCIRepository of every catalog model into an index entry.
+func BuildIndex(models []sourcev1b2.OCIRepository) (*Index, error) {
+	index := &Index{Version: IndexVersion, Models: []IndexEntry{}}
+	for i := range models {
+		entry, err := NewIndexEntry(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		index.Models = append(index.Models, *entry)
+	}
+	return index, nil
+}
+
+// NewIndexEntry describes a model from its labels and annotations, falling
+// back to the metadata of its artifact once it has been fetched. When an
+// annotation is invalid the entry is still returned, without that field,
+// together with the error.
+func NewIndexEntry(model *sourcev1b2.OCIRepository) (*IndexEntry, error) {
+	lookup := func(key string) string {
+		if v, ok := model.Labels[key]; ok {
+			return v
+		}
+		if v, ok := model.Annotations[key]; ok {
+			return v
+		}
+		if model.Status.Artifact != nil {
+			return model.Status.Artifact.Metadata[key]
+		}
+		return ""
+	}
+
+	entry := &IndexEntry{
+		Name:    model.Name,
+		URL:     model.Spec.URL,
+		Family:  lookup(FamilyLabel),
+		License: lookup(LicenseAnnotation),
+	}
+	if ref := model.Spec.Reference; ref != nil {
+		entry.Tag = ref.Tag
+		entry.Digest = ref.Digest
+	}
+	entry.Version, entry.Quantization, entry.Format = ParseTag(entry.Tag)
+
+	var errs []error
+	if v := lookup(ContextLengthAnnotation); v != "" {
+		n, err := ParseContextLength(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("model %s: %w", model.Name, err))
+		}
+		entry.ContextLength = n
+	}
+	if v := lookup(ParametersAnnotation); v != "" {
+		n, err := ParseParameters(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("model %s: %w", model.Name, err))
+		}
+		entry.Parameters = n
+	}
+	return entry, errors.Join(errs...)
+}
+
+// GuessFamily returns the family of a model from its name, such as the
+// general.name of a GGUF file. The name is matched against the models of
+// the index first, as fine-tunes like zephyr don't name their family, and
+// then against the families of the index.
+func GuessFamily(models []IndexEntry, name string) (string, bool) {
+	name = strings.NewReplacer("_", "-", " ", "-").Replace(strings.ToLower(name))
+	if name == "" {
+		return "", false
+	}
+
+	family, longest := "", 0
+	for _, m := range models {
+		if m.Family != "" && len(m.Name) > longest && strings.Contains(name, m.Name) {
+			family, longest = m.Family, len(m.Name)
+		}
+	}
+	if family != "" {
+		return family, true
+	}
+	for _, m := range models {
+		if len(m.Family) > longest && strings.Contains(name, m.Family) {
+			family, longest = m.Family, len(m.Family)
+		}
+	}
+	return family, family != ""
+}
+
+// ReadIndex decodes an index written by WriteIndex.
+func ReadIndex(r io.Reader) (*Index, error) {
+	index := &Index{}
+	if err := json.NewDecoder(r).Decode(index); err != nil {
+		return nil, fmt.Errorf("invalid catalog index: %w", err)
+	}
+	if index.Version != IndexVersion {
+		return nil, fmt.Errorf("unsupported catalog index version %q", index.Version)
+	}
+	return index, nil
+}
+
+// WriteIndex encodes the index as indented JSON.
+func WriteIndex(w io.Writer, index *Index) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(index)
+}
+
+// ParseTag splits a model tag such as v1.0.0-q5km-gguf into its version,
+// quantization (Q5_K_M) and file format (gguf). Parts that are missing
+// are returned empty.
+func ParseTag(tag string) (version, quantization, format string) {
+	parts := strings.Split(tag, "-")
+	if len(parts) > 0 && strings.HasPrefix(parts[0], "v") {
+		version, parts = parts[0], parts[1:]
+	}
+	for _, part := range parts {
+		switch {
+		case part == "gguf" || part == "ggml":
+			format = part
+		case quantization == "" && len(part) > 1 && part[0] == 'q' && part[1] >= '0' && part[1] <= '9':
+			quantization = formatQuantization(part)
+		}
+	}
+	return version, quantization, format
+}
+
+// formatQuantization spells a quantization the way llama.cpp does,
+// e.g. q5km becomes Q5_K_M and q80 becomes Q8_0.
+func formatQuantization(q string) string {
+	q = strings.ToUpper(strings.ReplaceAll(q, "_", ""))
+	out := q[:2]
+	rest := q[2:]
+	if strings.HasPrefix(rest, "K") {
+		out += "_K"
+		rest = rest[1:]
+	}
+	if rest != "" {
+		out += "_" + rest
+	}
+	return out
+}
+
+// ParseContextLength parses a context length such as 8192, 8k or 128K.
+func ParseContextLength(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	mult := int64(1)
+	if strings.HasSuffix(s, "k") || strings.HasSuffix(s, "K") {
+		s, mult = s[:len(s)-1], 1024
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid context length %q", s)
+	}
+	return n * mult, nil
+}
+
+// ParseParameters parses a parameter count such as 7B, 1.1B, 8x7B or 350M.
+func ParseParameters(s string) (int64, error) {
+	orig := s
+	s = strings.ToUpper(strings.TrimSpace(s))
+	experts := 1.0
+	if n, rest, ok := strings.Cut(s, "X"); ok {
+		e, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid parameter count %q", orig)
+		}
+		experts, s = e, rest
+	}
+
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "B"):
+		s, mult = s[:len(s)-1], 1e9
+	case strings.HasSuffix(s, "M"):
+		s, mult = s[:len(s)-1], 1e6
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid parameter count %q", orig)
+	}
+	return int64(math.Round(experts * n * mult)), nil
+}
+
+// FormatParameters prints a parameter count the way models are usually
+// named, e.g. 7B or 1.1B.
+func FormatParameters(n int64) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n >= 1e9:
+		return strconv.FormatFloat(float64(n)/1e9, 'f', -1, 64) + "B"
+	default:
+		return strconv.FormatFloat(float64(n)/1e6, 'f', -1, 64) + "M"
+	}
+}
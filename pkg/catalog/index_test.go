@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"bytes"
+	"testing"
+
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/weave-ai/weave-ai/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag, version, quantization, format string
+	}{
+		{"v1.0.0-q5km-gguf", "v1.0.0", "Q5_K_M", "gguf"},
+		{"v0.3.0-q3ks-gguf", "v0.3.0", "Q3_K_S", "gguf"},
+		{"v0.1.0-q8_0-gguf", "v0.1.0", "Q8_0", "gguf"},
+		{"latest", "", "", ""},
+	}
+	for _, tt := range tests {
+		version, quantization, format := ParseTag(tt.tag)
+		if version != tt.version || quantization != tt.quantization || format != tt.format {
+			t.Fatalf("ParseTag(%q) = %q, %q, %q, expected %q, %q, %q",
+				tt.tag, version, quantization, format, tt.version, tt.quantization, tt.format)
+		}
+	}
+}
+
+func TestParseParameters(t *testing.T) {
+	tests := map[string]int64{
+		"7B":    7_000_000_000,
+		"1.1B":  1_100_000_000,
+		"8x7B":  56_000_000_000,
+		"350M":  350_000_000,
+		"46.7b": 46_700_000_000,
+	}
+	for s, expected := range tests {
+		n, err := ParseParameters(s)
+		if err != nil {
+			t.Fatalf("ParseParameters(%q): %v", s, err)
+		}
+		if n != expected {
+			t.Fatalf("ParseParameters(%q) = %d, expected %d", s, n, expected)
+		}
+		if s == "7B" && FormatParameters(n) != s {
+			t.Fatalf("FormatParameters(%d) = %q, expected %q", n, FormatParameters(n), s)
+		}
+	}
+}
+
+func TestNewIndexEntryInvalidAnnotation(t *testing.T) {
+	model := &sourcev1b2.OCIRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "broken",
+			Labels: map[string]string{FamilyLabel: "llama"},
+			Annotations: map[string]string{
+				ContextLengthAnnotation: "lots",
+				ParametersAnnotation:    "7B",
+			},
+		},
+	}
+	entry, err := NewIndexEntry(model)
+	if err == nil {
+		t.Fatalf("expected an error for the invalid context length")
+	}
+	if entry == nil || entry.Family != "llama" || entry.Parameters != 7_000_000_000 || entry.ContextLength != 0 {
+		t.Fatalf("expected the entry without the context length, got %+v", entry)
+	}
+}
+
+func TestGuessFamily(t *testing.T) {
+	index, err := ReadIndex(bytes.NewReader(models.Index))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"huggingfaceh4_zephyr-7b-beta":   "mistral",
+		"mistralai_mistral-7b-v0.1":      "mistral",
+		"Mixtral 8x7B Instruct v0.1":     "mixtral",
+		"TinyLlama 1.1B Chat":            "llama",
+		"LLaMA v2":                       "llama",
+		"stabilityai_stablelm-zephyr-3b": "stablelm",
+		"my-own-model":                   "",
+	}
+	for name, expected := range tests {
+		family, ok := GuessFamily(index.Models, name)
+		if family != expected || ok != (expected != "") {
+			t.Fatalf("GuessFamily(%q) = %q, %v, expected %q", name, family, ok, expected)
+		}
+	}
+}
+
+func TestIndexIsUpToDate(t *testing.T) {
+	entries, err := Load(models.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := BuildIndex(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteIndex(&buf, index); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), models.Index) {
+		t.Fatalf("models/_index/index.json is out of date, run go generate ./models")
+	}
+}
@@ -0,0 +1,117 @@
+// Package chat talks to the OpenAI-compatible API served by the
+// inference engine of a LanguageModel.
+package chat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type Client struct {
+	// BaseURL of the engine, e.g. http://localhost:8000
+	BaseURL    string
+	Model      string
+	MaxTokens  int
+	HTTPClient *http.Client
+}
+
+type completionRequest struct {
+	Model     string    `json:"model,omitempty"`
+	Messages  []Message `json:"messages"`
+	MaxTokens int       `json:"max_tokens,omitempty"`
+	Stream    bool      `json:"stream"`
+}
+
+type completionChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// Stream sends the messages to /v1/chat/completions and calls onToken for
+// every piece of the answer as it arrives. It returns the whole answer.
+func (c *Client) Stream(ctx context.Context, messages []Message, onToken func(string)) (string, error) {
+	body, err := json.Marshal(completionRequest{
+		Model:     c.Model,
+		Messages:  messages,
+		MaxTokens: c.MaxTokens,
+		Stream:    true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.BaseURL, "/")+"/v1/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("chat completion failed with %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var answer strings.Builder
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:"); ok {
+			data = strings.TrimSpace(data)
+			if data == "[DONE]" {
+				return answer.String(), nil
+			}
+
+			chunk := completionChunk{}
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return answer.String(), fmt.Errorf("invalid completion chunk %q: %w", data, err)
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Delta.Content == "" {
+					continue
+				}
+				answer.WriteString(choice.Delta.Content)
+				if onToken != nil {
+					onToken(choice.Delta.Content)
+				}
+			}
+		}
+		if err == io.EOF {
+			return answer.String(), nil
+		}
+		if err != nil {
+			return answer.String(), err
+		}
+	}
+}
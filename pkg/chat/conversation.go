@@ -0,0 +1,63 @@
+package chat
+
+// Conversation keeps the history of a chat and fits it into the context
+// window of the model.
+type Conversation struct {
+	System   string
+	Messages []Message
+
+	// ContextSize is the context window of the model in tokens.
+	ContextSize int
+	// ReservedTokens are kept free for the answer of the model.
+	ReservedTokens int
+}
+
+func (c *Conversation) Add(role, content string) {
+	c.Messages = append(c.Messages, Message{Role: role, Content: content})
+}
+
+// Reset forgets the history but keeps the system prompt.
+func (c *Conversation) Reset() {
+	c.Messages = nil
+}
+
+// All returns the system prompt followed by the whole history.
+func (c *Conversation) All() []Message {
+	var messages []Message
+	if c.System != "" {
+		messages = append(messages, Message{Role: RoleSystem, Content: c.System})
+	}
+	return append(messages, c.Messages...)
+}
+
+// Prompt returns the messages to send to the model. The oldest messages are
+// dropped until the estimated size fits the context window, but the system
+// prompt and the latest message are always kept.
+func (c *Conversation) Prompt() []Message {
+	budget := c.ContextSize - c.ReservedTokens
+	if c.System != "" {
+		budget -= EstimateTokens(c.System)
+	}
+
+	start := len(c.Messages)
+	for start > 0 {
+		cost := EstimateTokens(c.Messages[start-1].Content)
+		if budget-cost < 0 && start < len(c.Messages) {
+			break
+		}
+		budget -= cost
+		start--
+	}
+
+	var messages []Message
+	if c.System != "" {
+		messages = append(messages, Message{Role: RoleSystem, Content: c.System})
+	}
+	return append(messages, c.Messages[start:]...)
+}
+
+// EstimateTokens approximates the number of tokens of a text, counting
+// roughly four characters per token plus the overhead of a chat message.
+func EstimateTokens(s string) int {
+	return len(s)/4 + 4
+}
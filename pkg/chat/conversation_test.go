@@ -0,0 +1,46 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptKeepsEverythingThatFits(t *testing.T) {
+	c := &Conversation{System: "be brief", ContextSize: 1000, ReservedTokens: 100}
+	c.Add(RoleUser, "hello")
+	c.Add(RoleAssistant, "hi")
+	c.Add(RoleUser, "how are you?")
+
+	prompt := c.Prompt()
+	if len(prompt) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(prompt))
+	}
+	if prompt[0].Role != RoleSystem {
+		t.Fatalf("expected the system prompt first, got %q", prompt[0].Role)
+	}
+}
+
+func TestPromptDropsOldestMessages(t *testing.T) {
+	c := &Conversation{System: "be brief", ContextSize: 200, ReservedTokens: 50}
+	long := strings.Repeat("x", 400) // about 100 tokens
+	c.Add(RoleUser, long)
+	c.Add(RoleAssistant, long)
+	c.Add(RoleUser, "last question")
+
+	prompt := c.Prompt()
+	if len(prompt) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(prompt))
+	}
+	if prompt[0].Role != RoleSystem || prompt[2].Content != "last question" {
+		t.Fatalf("expected the system prompt and the latest messages, got %v", prompt)
+	}
+}
+
+func TestPromptAlwaysKeepsLatestMessage(t *testing.T) {
+	c := &Conversation{ContextSize: 10}
+	c.Add(RoleUser, strings.Repeat("x", 400))
+
+	if prompt := c.Prompt(); len(prompt) != 1 {
+		t.Fatalf("expected the latest message to be kept, got %d messages", len(prompt))
+	}
+}
@@ -0,0 +1,444 @@
+// Package gguf reads the header and the key/value metadata of GGUF model
+// files, the format of llama.cpp, without loading their tensors.
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Magic is the first four bytes of a GGUF file.
+const Magic = "GGUF"
+
+// ValueType is the type of a metadata value.
+type ValueType uint32
+
+const (
+	TypeUint8 ValueType = iota
+	TypeInt8
+	TypeUint16
+	TypeInt16
+	TypeUint32
+	TypeInt32
+	TypeFloat32
+	TypeBool
+	TypeString
+	TypeArray
+	TypeUint64
+	TypeInt64
+	TypeFloat64
+)
+
+var typeNames = []string{"uint8", "int8", "uint16", "int16", "uint32", "int32", "float32", "bool", "string", "array", "uint64", "int64", "float64"}
+
+func (t ValueType) String() string {
+	if int(t) < len(typeNames) {
+		return typeNames[t]
+	}
+	return fmt.Sprintf("type(%d)", uint32(t))
+}
+
+// MarshalText makes the type readable in JSON.
+func (t ValueType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// MaxArrayValues is the number of values kept of an array. The tokenizer
+// vocabularies hold tens of thousands of entries that nobody wants to print,
+// their length is always kept.
+const MaxArrayValues = 64
+
+// limits guarding against corrupted files
+const (
+	maxStringLength = 16 << 20
+	maxCount        = 1 << 24
+	maxDimensions   = 8
+)
+
+// Array is an array value, with at most MaxArrayValues of its values.
+type Array struct {
+	Type   ValueType `json:"type"`
+	Len    uint64    `json:"length"`
+	Values []any     `json:"values"`
+}
+
+// KV is a metadata entry.
+type KV struct {
+	Key   string    `json:"key"`
+	Type  ValueType `json:"type"`
+	Value any       `json:"value"`
+}
+
+// TensorInfo describes a tensor, the tensor data itself is never read.
+type TensorInfo struct {
+	Name       string   `json:"name"`
+	Dimensions []uint64 `json:"dimensions"`
+	Type       uint32   `json:"type"`
+	Offset     uint64   `json:"offset"`
+}
+
+// File is the header of a GGUF file.
+type File struct {
+	Version   uint32       `json:"version"`
+	BigEndian bool         `json:"bigEndian,omitempty"`
+	Metadata  []KV         `json:"metadata"`
+	Tensors   []TensorInfo `json:"tensors"`
+}
+
+// ReadFile reads the header of the GGUF file at path.
+func ReadFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads the header of a GGUF file from r, stopping right before the
+// tensor data, so r can be a stream such as a download.
+func Read(r io.Reader) (*File, error) {
+	d := &decoder{r: bufio.NewReaderSize(r, 64<<10), order: binary.LittleEndian}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(d.r, magic[:]); err != nil {
+		return nil, fmt.Errorf("reading GGUF magic: %w", err)
+	}
+	if string(magic[:]) != Magic {
+		return nil, fmt.Errorf("not a GGUF file, magic is %q", magic[:])
+	}
+
+	f := &File{}
+	var version [4]byte
+	if _, err := io.ReadFull(d.r, version[:]); err != nil {
+		return nil, err
+	}
+	f.Version = binary.LittleEndian.Uint32(version[:])
+	if f.Version&0xFFFF == 0 {
+		// big endian files have the version bytes reversed
+		d.order = binary.BigEndian
+		f.BigEndian = true
+		f.Version = binary.BigEndian.Uint32(version[:])
+	}
+	if f.Version != 2 && f.Version != 3 {
+		return nil, fmt.Errorf("unsupported GGUF version %d, only versions 2 and 3 are supported", f.Version)
+	}
+
+	tensorCount, err := d.count()
+	if err != nil {
+		return nil, fmt.Errorf("reading tensor count: %w", err)
+	}
+	kvCount, err := d.count()
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata count: %w", err)
+	}
+
+	for i := uint64(0); i < kvCount; i++ {
+		key, err := d.string()
+		if err != nil {
+			return nil, fmt.Errorf("reading metadata key %d: %w", i, err)
+		}
+		t, err := d.uint32()
+		if err != nil {
+			return nil, fmt.Errorf("reading type of %s: %w", key, err)
+		}
+		v, err := d.value(ValueType(t))
+		if err != nil {
+			return nil, fmt.Errorf("reading value of %s: %w", key, err)
+		}
+		f.Metadata = append(f.Metadata, KV{Key: key, Type: ValueType(t), Value: v})
+	}
+
+	for i := uint64(0); i < tensorCount; i++ {
+		info, err := d.tensorInfo()
+		if err != nil {
+			return nil, fmt.Errorf("reading tensor info %d: %w", i, err)
+		}
+		f.Tensors = append(f.Tensors, info)
+	}
+	return f, nil
+}
+
+// Get returns the value of a metadata key.
+func (f *File) Get(key string) (any, bool) {
+	for _, kv := range f.Metadata {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// GetString returns the value of a string metadata key.
+func (f *File) GetString(key string) string {
+	v, _ := f.Get(key)
+	s, _ := v.(string)
+	return s
+}
+
+// GetUint returns the value of an integer metadata key.
+func (f *File) GetUint(key string) (uint64, bool) {
+	v, ok := f.Get(key)
+	if !ok {
+		return 0, false
+	}
+	switch v := v.(type) {
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case int8:
+		return uint64(v), v >= 0
+	case int16:
+		return uint64(v), v >= 0
+	case int32:
+		return uint64(v), v >= 0
+	case int64:
+		return uint64(v), v >= 0
+	}
+	return 0, false
+}
+
+// Architecture returns the model architecture, e.g. llama.
+func (f *File) Architecture() string {
+	return f.GetString("general.architecture")
+}
+
+// Name returns the name of the model, if the file records it.
+func (f *File) Name() string {
+	return f.GetString("general.name")
+}
+
+// BaseName returns the name of the base model, e.g. Mistral, if the file
+// records it.
+func (f *File) BaseName() string {
+	return f.GetString("general.basename")
+}
+
+// ContextLength returns the context length the model was trained with.
+func (f *File) ContextLength() uint64 {
+	n, _ := f.GetUint(f.Architecture() + ".context_length")
+	return n
+}
+
+// Quantization returns the name of the file type, e.g. Q5_K_M.
+func (f *File) Quantization() string {
+	n, ok := f.GetUint("general.file_type")
+	if !ok {
+		return ""
+	}
+	if int(n) < len(fileTypes) && fileTypes[n] != "" {
+		return fileTypes[n]
+	}
+	return fmt.Sprintf("type(%d)", n)
+}
+
+// ParameterCount returns the number of parameters of the model, summed
+// over the shapes of its tensors.
+func (f *File) ParameterCount() uint64 {
+	var total uint64
+	for _, t := range f.Tensors {
+		n := uint64(1)
+		for _, d := range t.Dimensions {
+			n *= d
+		}
+		total += n
+	}
+	return total
+}
+
+// fileTypes are the names of general.file_type, as llama.cpp spells them.
+var fileTypes = []string{
+	0:  "F32",
+	1:  "F16",
+	2:  "Q4_0",
+	3:  "Q4_1",
+	4:  "Q4_1_SOME_F16",
+	7:  "Q8_0",
+	8:  "Q5_0",
+	9:  "Q5_1",
+	10: "Q2_K",
+	11: "Q3_K_S",
+	12: "Q3_K_M",
+	13: "Q3_K_L",
+	14: "Q4_K_S",
+	15: "Q4_K_M",
+	16: "Q5_K_S",
+	17: "Q5_K_M",
+	18: "Q6_K",
+	19: "IQ2_XXS",
+	20: "IQ2_XS",
+}
+
+type decoder struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if _, err := io.ReadFull(d.r, d.buf[:n]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return d.buf[:n], nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	b, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint64(b), nil
+}
+
+func (d *decoder) count() (uint64, error) {
+	n, err := d.uint64()
+	if err != nil {
+		return 0, err
+	}
+	if n > maxCount {
+		return 0, fmt.Errorf("count %d is too large, the file is likely corrupted", n)
+	}
+	return n, nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint64()
+	if err != nil {
+		return "", err
+	}
+	if n > maxStringLength {
+		return "", fmt.Errorf("string of %d bytes is too large, the file is likely corrupted", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+	return string(b), nil
+}
+
+func (d *decoder) value(t ValueType) (any, error) {
+	switch t {
+	case TypeUint8, TypeInt8, TypeBool:
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case TypeInt8:
+			return int8(b[0]), nil
+		case TypeBool:
+			return b[0] != 0, nil
+		}
+		return b[0], nil
+	case TypeUint16, TypeInt16:
+		b, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		if t == TypeInt16 {
+			return int16(d.order.Uint16(b)), nil
+		}
+		return d.order.Uint16(b), nil
+	case TypeUint32, TypeInt32, TypeFloat32:
+		v, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case TypeInt32:
+			return int32(v), nil
+		case TypeFloat32:
+			return math.Float32frombits(v), nil
+		}
+		return v, nil
+	case TypeUint64, TypeInt64, TypeFloat64:
+		v, err := d.uint64()
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case TypeInt64:
+			return int64(v), nil
+		case TypeFloat64:
+			return math.Float64frombits(v), nil
+		}
+		return v, nil
+	case TypeString:
+		return d.string()
+	case TypeArray:
+		return d.array()
+	}
+	return nil, fmt.Errorf("unknown value type %d", uint32(t))
+}
+
+func (d *decoder) array() (*Array, error) {
+	t, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	n, err := d.count()
+	if err != nil {
+		return nil, err
+	}
+	a := &Array{Type: ValueType(t), Len: n}
+	for i := uint64(0); i < n; i++ {
+		v, err := d.value(a.Type)
+		if err != nil {
+			return nil, err
+		}
+		if i < MaxArrayValues {
+			a.Values = append(a.Values, v)
+		}
+	}
+	return a, nil
+}
+
+func (d *decoder) tensorInfo() (TensorInfo, error) {
+	var info TensorInfo
+	var err error
+	if info.Name, err = d.string(); err != nil {
+		return info, err
+	}
+	dims, err := d.uint32()
+	if err != nil {
+		return info, err
+	}
+	if dims > maxDimensions {
+		return info, fmt.Errorf("tensor %s has %d dimensions, the file is likely corrupted", info.Name, dims)
+	}
+	for i := uint32(0); i < dims; i++ {
+		dim, err := d.uint64()
+		if err != nil {
+			return info, err
+		}
+		info.Dimensions = append(info.Dimensions, dim)
+	}
+	if info.Type, err = d.uint32(); err != nil {
+		return info, err
+	}
+	if info.Offset, err = d.uint64(); err != nil {
+		return info, err
+	}
+	return info, nil
+}
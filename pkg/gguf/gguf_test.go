@@ -0,0 +1,102 @@
+package gguf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+type testWriter struct {
+	bytes.Buffer
+	order binary.ByteOrder
+}
+
+func (w *testWriter) put(v any) {
+	if s, ok := v.(string); ok {
+		w.put(uint64(len(s)))
+		w.WriteString(s)
+		return
+	}
+	binary.Write(&w.Buffer, w.order, v)
+}
+
+func testFile(order binary.ByteOrder, version uint32) []byte {
+	w := &testWriter{order: order}
+	w.WriteString(Magic)
+	w.put(version)
+	w.put(uint64(2)) // tensors
+	w.put(uint64(4)) // metadata
+
+	w.put("general.architecture")
+	w.put(uint32(TypeString))
+	w.put("llama")
+
+	w.put("llama.context_length")
+	w.put(uint32(TypeUint32))
+	w.put(uint32(4096))
+
+	w.put("general.file_type")
+	w.put(uint32(TypeUint32))
+	w.put(uint32(17))
+
+	w.put("tokenizer.ggml.tokens")
+	w.put(uint32(TypeArray))
+	w.put(uint32(TypeString))
+	w.put(uint64(100))
+	for i := 0; i < 100; i++ {
+		w.put("tok")
+	}
+
+	for _, name := range []string{"token_embd.weight", "output.weight"} {
+		w.put(name)
+		w.put(uint32(2))
+		w.put(uint64(4096))
+		w.put(uint64(32000))
+		w.put(uint32(14))
+		w.put(uint64(0))
+	}
+
+	// tensor data, which must never be read
+	w.Write(bytes.Repeat([]byte{0xff}, 32))
+	return w.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for _, version := range []uint32{2, 3} {
+			f, err := Read(bytes.NewReader(testFile(order, version)))
+			if err != nil {
+				t.Fatalf("%s v%d: %v", order, version, err)
+			}
+			if f.Version != version {
+				t.Fatalf("expected version %d, got %d", version, f.Version)
+			}
+			if f.Architecture() != "llama" || f.ContextLength() != 4096 || f.Quantization() != "Q5_K_M" {
+				t.Fatalf("%s v%d: unexpected metadata %q %d %q", order, version, f.Architecture(), f.ContextLength(), f.Quantization())
+			}
+			if got := f.ParameterCount(); got != 2*4096*32000 {
+				t.Fatalf("unexpected parameter count %d", got)
+			}
+			v, _ := f.Get("tokenizer.ggml.tokens")
+			tokens, ok := v.(*Array)
+			if !ok || tokens.Len != 100 || len(tokens.Values) != MaxArrayValues {
+				t.Fatalf("unexpected tokens %#v", v)
+			}
+		}
+	}
+}
+
+func TestReadRejectsInvalidFiles(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("GGML\x03\x00\x00\x00"))); err == nil {
+		t.Fatalf("expected an error for a bad magic")
+	}
+	if _, err := Read(bytes.NewReader(testFile(binary.LittleEndian, 1))); err == nil {
+		t.Fatalf("expected an error for GGUF version 1")
+	}
+	data := testFile(binary.LittleEndian, 3)
+	if _, err := Read(bytes.NewReader(data[:60])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected an unexpected EOF for a truncated file, got %v", err)
+	}
+}
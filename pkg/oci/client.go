@@ -0,0 +1,89 @@
+// Package oci pulls and pushes model artifacts in the Flux OCI format.
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+const (
+	// ContentMediaType is the media type of the layer holding the model
+	// files, the one selected by the layerSelector of the catalog models.
+	ContentMediaType = "application/vnd.cncf.flux.content.v1.tar+gzip"
+
+	// ConfigMediaType is the media type of the config of Flux artifacts.
+	ConfigMediaType = "application/vnd.cncf.flux.config.v1+json"
+)
+
+// Client talks to OCI registries with the credentials of the docker config.
+type Client struct {
+	// Insecure allows plain HTTP registries, registries on localhost
+	// are always reached through plain HTTP.
+	Insecure bool
+}
+
+// ParseReference parses an OCI reference with or without the oci:// prefix.
+func (c *Client) ParseReference(ref string) (name.Reference, error) {
+	var opts []name.Option
+	if c.Insecure {
+		opts = append(opts, name.Insecure)
+	}
+	parsed, err := name.ParseReference(strings.TrimPrefix(ref, "oci://"), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI reference %q: %w", ref, err)
+	}
+	return parsed, nil
+}
+
+func (c *Client) options(ctx context.Context) []remote.Option {
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithUserAgent("weave-ai"),
+	}
+}
+
+// Manifest fetches the image manifest a reference points at.
+func (c *Client) Manifest(ctx context.Context, ref name.Reference) (*v1.Manifest, v1.Hash, error) {
+	img, err := remote.Image(ref, c.options(ctx)...)
+	if err != nil {
+		return nil, v1.Hash{}, fmt.Errorf("fetching manifest of %s failed: %w", ref, err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, v1.Hash{}, err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, v1.Hash{}, err
+	}
+	return manifest, digest, nil
+}
+
+// SelectLayer returns the first layer with the given media type, the way
+// the layerSelector of an OCIRepository does.
+func SelectLayer(manifest *v1.Manifest, mediaType string) (v1.Descriptor, error) {
+	for _, layer := range manifest.Layers {
+		if string(layer.MediaType) == mediaType {
+			return layer, nil
+		}
+	}
+	return v1.Descriptor{}, fmt.Errorf("no layer with media type %s found", mediaType)
+}
+
+// transport returns an authenticated HTTP transport for a repository.
+func (c *Client) transport(ctx context.Context, repo name.Repository, scope string) (http.RoundTripper, error) {
+	auth, err := authn.DefaultKeychain.Resolve(repo.Registry)
+	if err != nil {
+		return nil, err
+	}
+	return transport.NewWithContext(ctx, repo.Registry, auth, remote.DefaultTransport, []string{repo.Scope(scope)})
+}
@@ -0,0 +1,247 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// RefNameAnnotation records, in the index of an OCI layout, the reference
+// an image was fetched from.
+const RefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// Fetch returns the image a reference points at. For multi-platform images
+// the manifest of platform is selected.
+func (c *Client) Fetch(ctx context.Context, ref name.Reference, platform v1.Platform) (v1.Image, error) {
+	img, err := remote.Image(ref, append(c.options(ctx), remote.WithPlatform(platform))...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s failed: %w", ref, err)
+	}
+	return img, nil
+}
+
+// PushImage uploads an image, e.g. one read from an OCI layout.
+func (c *Client) PushImage(ctx context.Context, ref name.Reference, img v1.Image, progress func(done, total int64)) error {
+	opts := c.options(ctx)
+	if progress != nil {
+		updates := make(chan v1.Update, 16)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for u := range updates {
+				progress(u.Complete, u.Total)
+			}
+		}()
+		defer func() { <-done }()
+		opts = append(opts, remote.WithProgress(updates))
+	}
+
+	if err := remote.Write(ref, img, opts...); err != nil {
+		return fmt.Errorf("pushing %s failed: %w", ref, err)
+	}
+	return nil
+}
+
+// LayoutWriter writes an OCI image layout into a tar archive, so that
+// images are streamed from the registry into the archive without being
+// stored on disk first. Blobs shared by several images are written once.
+type LayoutWriter struct {
+	tw        *tar.Writer
+	dir       string
+	blobs     map[v1.Hash]bool
+	manifests []v1.Descriptor
+}
+
+// NewLayoutWriter returns a writer of an OCI layout under dir in tw.
+func NewLayoutWriter(tw *tar.Writer, dir string) *LayoutWriter {
+	return &LayoutWriter{tw: tw, dir: dir, blobs: map[v1.Hash]bool{}}
+}
+
+// WriteImage adds an image to the layout, recording ref in its annotations.
+func (w *LayoutWriter) WriteImage(img v1.Image, ref string, progress func(done, total int64)) error {
+	layers, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	var done, total int64
+	for _, layer := range layers {
+		size, err := layer.Size()
+		if err != nil {
+			return err
+		}
+		total += size
+	}
+
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return err
+		}
+		size, err := layer.Size()
+		if err != nil {
+			return err
+		}
+		if w.blobs[digest] {
+			done += size
+			continue
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			return err
+		}
+		var r io.Reader = rc
+		if progress != nil {
+			offset := done
+			r = io.TeeReader(rc, writerFunc(func(n int) {
+				offset += int64(n)
+				progress(offset, total)
+			}))
+		}
+		err = w.writeBlob(digest, size, r)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("writing layer %s of %s: %w", digest, ref, err)
+		}
+		done += size
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+	config, err := img.RawConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := w.writeBytes(manifest.Config.Digest, config); err != nil {
+		return err
+	}
+
+	raw, err := img.RawManifest()
+	if err != nil {
+		return err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	mediaType, err := img.MediaType()
+	if err != nil {
+		return err
+	}
+	if err := w.writeBytes(digest, raw); err != nil {
+		return err
+	}
+	w.manifests = append(w.manifests, v1.Descriptor{
+		MediaType:   mediaType,
+		Size:        int64(len(raw)),
+		Digest:      digest,
+		Annotations: map[string]string{RefNameAnnotation: ref},
+	})
+	return nil
+}
+
+// Close writes the index of the layout. It doesn't close the tar writer.
+func (w *LayoutWriter) Close() error {
+	if err := w.writeFile("oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)); err != nil {
+		return err
+	}
+	index, err := json.MarshalIndent(v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+		Manifests:     w.manifests,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.writeFile("index.json", index)
+}
+
+func (w *LayoutWriter) writeBytes(digest v1.Hash, data []byte) error {
+	if w.blobs[digest] {
+		return nil
+	}
+	return w.writeBlob(digest, int64(len(data)), bytes.NewReader(data))
+}
+
+func (w *LayoutWriter) writeBlob(digest v1.Hash, size int64, r io.Reader) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:     path.Join(w.dir, "blobs", digest.Algorithm, digest.Hex),
+		Mode:     0o644,
+		Size:     size,
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w.tw, r); err != nil {
+		return err
+	}
+	w.blobs[digest] = true
+	return nil
+}
+
+func (w *LayoutWriter) writeFile(name string, data []byte) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:     path.Join(w.dir, name),
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+// LayoutImage is an image of an OCI layout and the reference it was
+// fetched from.
+type LayoutImage struct {
+	Ref   string
+	Image v1.Image
+}
+
+// ReadLayout returns the images of the OCI layout in dir.
+func ReadLayout(dir string) ([]LayoutImage, error) {
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading OCI layout %s: %w", dir, err)
+	}
+	index, err := p.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var images []LayoutImage
+	for _, desc := range manifest.Manifests {
+		img, err := index.Image(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, LayoutImage{Ref: desc.Annotations[RefNameAnnotation], Image: img})
+	}
+	return images, nil
+}
+
+type writerFunc func(n int)
+
+func (f writerFunc) Write(b []byte) (int, error) {
+	f(len(b))
+	return len(b), nil
+}
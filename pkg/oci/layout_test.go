@@ -0,0 +1,94 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestLayoutRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.gguf")
+	if err := os.WriteFile(model, []byte("GGUF fake model"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "model.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Archive(model, f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	c := &Client{}
+	ctx := context.Background()
+	src, err := c.ParseReference(host + "/models/fake:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed, err := c.Push(ctx, src, archive, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := c.Fetch(ctx, src, v1.Platform{OS: "linux", Architecture: "amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	lw := NewLayoutWriter(tw, "oci")
+	// the second write shares every blob with the first one
+	for _, ref := range []string{src.String(), src.String() + "-copy"} {
+		if err := lw.WriteImage(img, ref, nil); err != nil {
+			t.Fatalf("writing layout: %v", err)
+		}
+	}
+	if err := lw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := t.TempDir()
+	if err := ExtractTar(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	images, err := ReadLayout(filepath.Join(out, "oci"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 2 || images[0].Ref != src.String() {
+		t.Fatalf("unexpected layout images %+v", images)
+	}
+
+	dst, err := c.ParseReference(host + "/mirror/models/fake:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PushImage(ctx, dst, images[0].Image, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, digest, err := c.Manifest(ctx, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != pushed {
+		t.Fatalf("mirrored %s but the original is %s", digest, pushed)
+	}
+}
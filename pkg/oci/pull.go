@@ -0,0 +1,220 @@
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// DownloadBlob downloads a blob into file, resuming from the bytes already
+// present in file+".partial", and verifies its digest before moving it into place.
+func (c *Client) DownloadBlob(ctx context.Context, repo name.Repository, desc v1.Descriptor, file string, progress func(done, total int64)) error {
+	if desc.Digest.Algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm %s", desc.Digest.Algorithm)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	partial := file + ".partial"
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if offset > desc.Size {
+		// not the blob we expect, start over
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		offset = 0
+	}
+
+	if offset < desc.Size {
+		if err := c.fetchBlob(ctx, repo, desc, f, offset, progress); err != nil {
+			return err
+		}
+	}
+
+	if err := verifyDigest(f, desc); err != nil {
+		// a corrupted download can't be resumed
+		f.Close()
+		os.Remove(partial)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partial, file)
+}
+
+// OpenBlob streams a blob from the registry, for reading the beginning of a
+// layer without downloading all of it. The digest is not verified.
+func (c *Client) OpenBlob(ctx context.Context, repo name.Repository, desc v1.Descriptor) (io.ReadCloser, error) {
+	resp, err := c.getBlob(ctx, repo, desc, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := transport.CheckError(resp, http.StatusOK); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading blob %s failed: %w", desc.Digest, err)
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) getBlob(ctx context.Context, repo name.Repository, desc v1.Descriptor, offset int64) (*http.Response, error) {
+	rt, err := c.transport(ctx, repo, transport.PullScope)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s://%s/v2/%s/blobs/%s", repo.Registry.Scheme(), repo.RegistryStr(), repo.RepositoryStr(), desc.Digest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return (&http.Client{Transport: rt}).Do(req)
+}
+
+func (c *Client) fetchBlob(ctx context.Context, repo name.Repository, desc v1.Descriptor, f *os.File, offset int64, progress func(done, total int64)) error {
+	resp, err := c.getBlob(ctx, repo, desc, offset)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// the registry ignored the range, start over
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		offset = 0
+	default:
+		if err := transport.CheckError(resp, http.StatusOK, http.StatusPartialContent); err != nil {
+			return fmt.Errorf("downloading blob %s failed: %w", desc.Digest, err)
+		}
+	}
+
+	var w io.Writer = f
+	if progress != nil {
+		w = &progressWriter{w: f, done: offset, total: desc.Size, fn: progress}
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("downloading blob %s interrupted, run again to resume: %w", desc.Digest, err)
+	}
+	return nil
+}
+
+func verifyDigest(f *os.File, desc v1.Descriptor) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	if n != desc.Size {
+		return fmt.Errorf("blob %s has size %d, expected %d", desc.Digest, n, desc.Size)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != desc.Digest.Hex {
+		return fmt.Errorf("blob digest mismatch: expected %s, got sha256:%s", desc.Digest, got)
+	}
+	return nil
+}
+
+type progressWriter struct {
+	w           io.Writer
+	done, total int64
+	fn          func(done, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	p.fn(p.done, p.total)
+	return n, err
+}
+
+// Extract unpacks a tar+gzip archive into dir, refusing entries that
+// would escape it.
+func Extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return ExtractTar(gz, dir)
+}
+
+// ExtractTar unpacks an uncompressed tar archive into dir, refusing entries
+// that would escape it.
+func ExtractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if target != filepath.Clean(dir) && !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid archive entry %q", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode)&0o755|0o600)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			// links and devices have no place in a model artifact
+		}
+	}
+}
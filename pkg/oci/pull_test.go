@@ -0,0 +1,151 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func testArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPullFromLocalRegistry(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+
+	data := testArchive(t, map[string]string{"model.gguf": "GGUF fake model"})
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, ConfigMediaType)
+	img, err := mutate.AppendLayers(img, static.NewLayer(data, ContentMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{}
+	ref, err := c.ParseReference("oci://" + strings.TrimPrefix(srv.URL, "http://") + "/models/fake:v0.1.0-q4km-gguf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, _, err := c.Manifest(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("fetching manifest: %v", err)
+	}
+	layer, err := SelectLayer(manifest, ContentMediaType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pretend an earlier download was interrupted halfway
+	dir := t.TempDir()
+	blob := filepath.Join(dir, "blob")
+	if err := os.WriteFile(blob+".partial", data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.DownloadBlob(context.Background(), ref.Context(), layer, blob, nil); err != nil {
+		t.Fatalf("downloading blob: %v", err)
+	}
+	got, err := os.ReadFile(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("downloaded blob differs from the pushed one")
+	}
+
+	f, err := os.Open(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out := filepath.Join(dir, "out")
+	if err := Extract(f, out); err != nil {
+		t.Fatalf("extracting: %v", err)
+	}
+	model, err := os.ReadFile(filepath.Join(out, "model.gguf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(model) != "GGUF fake model" {
+		t.Fatalf("unexpected model content %q", model)
+	}
+}
+
+func TestDownloadBlobRejectsCorruptedPartial(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+
+	data := testArchive(t, map[string]string{"model.gguf": "GGUF"})
+	layer := static.NewLayer(data, ContentMediaType)
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{}
+	ref, err := c.ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/models/fake:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _, err := c.Manifest(context.Background(), ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob := filepath.Join(t.TempDir(), "blob")
+	corrupted := bytes.Repeat([]byte{0}, len(data))
+	if err := os.WriteFile(blob+".partial", corrupted, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DownloadBlob(context.Background(), ref.Context(), manifest.Layers[0], blob, nil); err == nil {
+		t.Fatalf("expected a digest mismatch")
+	}
+	if _, err := os.Stat(blob + ".partial"); !os.IsNotExist(err) {
+		t.Fatalf("expected the corrupted partial download to be removed")
+	}
+}
+
+func TestExtractRejectsEscapingEntries(t *testing.T) {
+	data := testArchive(t, map[string]string{"../evil": "x"})
+	if err := Extract(bytes.NewReader(data), t.TempDir()); err == nil {
+		t.Fatalf("expected an error for an entry escaping the target directory")
+	}
+}
@@ -0,0 +1,113 @@
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Annotations of the manifest of Flux artifacts.
+const (
+	CreatedAnnotation  = "org.opencontainers.image.created"
+	SourceAnnotation   = "org.opencontainers.image.source"
+	RevisionAnnotation = "org.opencontainers.image.revision"
+)
+
+// Archive writes path, a file or a directory, to w as a tar+gzip archive
+// with the paths relative to it.
+func Archive(path string, w io.Writer) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	root := path
+	if !info.IsDir() {
+		root = filepath.Dir(path)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			// directories are implied by the paths of the files
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		hdr := &tar.Header{
+			Name:     filepath.ToSlash(rel),
+			Mode:     int64(info.Mode().Perm()),
+			Size:     info.Size(),
+			Typeflag: tar.TypeReg,
+			ModTime:  info.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Push uploads the tar+gzip archive in file as a Flux artifact, with the
+// annotations set on its manifest, and returns the digest of the manifest.
+func (c *Client) Push(ctx context.Context, ref name.Reference, file string, annotations map[string]string, progress func(done, total int64)) (v1.Hash, error) {
+	layer, err := tarball.LayerFromFile(file, tarball.WithMediaType(ContentMediaType))
+	if err != nil {
+		return v1.Hash{}, err
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if _, ok := annotations[CreatedAnnotation]; !ok {
+		annotations[CreatedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, ConfigMediaType)
+	img = mutate.Annotations(img, annotations).(v1.Image)
+	img, err = mutate.AppendLayers(img, layer)
+	if err != nil {
+		return v1.Hash{}, err
+	}
+
+	if err := c.PushImage(ctx, ref, img, progress); err != nil {
+		return v1.Hash{}, err
+	}
+	return img.Digest()
+}
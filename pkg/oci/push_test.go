@@ -0,0 +1,89 @@
+package oci
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func TestPushThenPull(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.gguf")
+	if err := os.WriteFile(model, []byte("GGUF fake model"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "model.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Archive(model, f); err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{}
+	ref, err := c.ParseReference("oci://" + strings.TrimPrefix(srv.URL, "http://") + "/models/fake:v1.0.0-q4km-gguf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reported bool
+	pushed, err := c.Push(context.Background(), ref, archive, map[string]string{
+		"ai.contrib.fluxcd.io/family": "llama",
+	}, func(done, total int64) { reported = true })
+	if err != nil {
+		t.Fatalf("pushing: %v", err)
+	}
+	if !reported {
+		t.Fatalf("expected progress to be reported")
+	}
+
+	manifest, digest, err := c.Manifest(context.Background(), ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != pushed {
+		t.Fatalf("pushed %s but the registry has %s", pushed, digest)
+	}
+	if string(manifest.Config.MediaType) != ConfigMediaType {
+		t.Fatalf("unexpected config media type %s", manifest.Config.MediaType)
+	}
+	if manifest.Annotations["ai.contrib.fluxcd.io/family"] != "llama" || manifest.Annotations[CreatedAnnotation] == "" {
+		t.Fatalf("unexpected annotations %v", manifest.Annotations)
+	}
+	layer, err := SelectLayer(manifest, ContentMediaType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob := filepath.Join(dir, "blob")
+	if err := c.DownloadBlob(context.Background(), ref.Context(), layer, blob, nil); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.Open(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	out := filepath.Join(dir, "out")
+	if err := Extract(b, out); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(out, "model.gguf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "GGUF fake model" {
+		t.Fatalf("unexpected model content %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	runclient "github.com/fluxcd/pkg/runtime/client"
+	"github.com/fluxcd/pkg/ssa"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// FieldChange is a field whose value would change. Added fields have no
+// Old value and removed ones no New value, which Added and Removed tell
+// apart from fields set to an empty string.
+type FieldChange struct {
+	Path    string
+	Old     string
+	New     string
+	Added   bool
+	Removed bool
+}
+
+// ObjectDiff is what applying an object would do.
+type ObjectDiff struct {
+	Entry   ssa.ChangeSetEntry
+	Changes []FieldChange
+}
+
+// Diff dry-runs a server-side apply of the resources and returns the
+// objects that would be created or configured, with their changed fields.
+// Unchanged objects are left out.
+func Diff(ctx context.Context, rcg genericclioptions.RESTClientGetter, opts *runclient.Options, resources []byte) ([]ObjectDiff, error) {
+	objs, err := ssa.ReadObjects(bytes.NewReader(resources))
+	if err != nil {
+		return nil, err
+	}
+	if err := ssa.SetNativeKindsDefaults(objs); err != nil {
+		return nil, err
+	}
+
+	man, err := newManager(rcg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []ObjectDiff
+	for _, obj := range objs {
+		entry, existing, merged, err := man.Diff(ctx, obj, ssa.DefaultDiffOptions())
+		if err != nil {
+			return nil, err
+		}
+		switch entry.Action {
+		case ssa.CreatedAction:
+			diffs = append(diffs, ObjectDiff{Entry: *entry})
+		case ssa.ConfiguredAction:
+			diffs = append(diffs, ObjectDiff{Entry: *entry, Changes: fieldChanges(existing, merged)})
+		}
+	}
+	return diffs, nil
+}
+
+// fields that change on every write, or that apply doesn't set
+var ignoredDiffFields = []string{
+	"metadata.generation",
+	"metadata.resourceVersion",
+	"metadata.managedFields",
+	"status",
+}
+
+func fieldChanges(existing, merged *unstructured.Unstructured) []FieldChange {
+	before := map[string]string{}
+	after := map[string]string{}
+	flatten("", existing.Object, before)
+	flatten("", merged.Object, after)
+
+	var changes []FieldChange
+	for path, old := range before {
+		if n, ok := after[path]; !ok || n != old {
+			changes = append(changes, FieldChange{Path: path, Old: old, New: n, Removed: !ok})
+		}
+	}
+	for path, n := range after {
+		if _, ok := before[path]; !ok {
+			changes = append(changes, FieldChange{Path: path, New: n, Added: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func flatten(path string, v interface{}, out map[string]string) {
+	for _, ignored := range ignoredDiffFields {
+		if path == ignored {
+			return
+		}
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			p := key
+			if path != "" {
+				p = path + "." + key
+			}
+			flatten(p, value, out)
+		}
+	case []interface{}:
+		for i, value := range v {
+			flatten(fmt.Sprintf("%s[%d]", path, i), value, out)
+		}
+	default:
+		s := fmt.Sprint(v)
+		if strings.Contains(s, "\n") {
+			// multi-line strings such as scripts would not fit on a line
+			s = fmt.Sprintf("%q", s)
+		}
+		out[path] = s
+	}
+}
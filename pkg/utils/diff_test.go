@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFieldChanges(t *testing.T) {
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "lm-controller", "resourceVersion": "1"},
+		"spec": map[string]interface{}{
+			"image":   "lm-controller:v0.9.0",
+			"prefix":  "",
+			"removed": "yes",
+		},
+	}}
+	merged := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "lm-controller", "resourceVersion": "2"},
+		"spec": map[string]interface{}{
+			"image":  "lm-controller:v0.10.0",
+			"prefix": "llm-",
+			"added":  "",
+		},
+	}}
+
+	expected := []FieldChange{
+		{Path: "spec.added", New: "", Added: true},
+		{Path: "spec.image", Old: "lm-controller:v0.9.0", New: "lm-controller:v0.10.0"},
+		{Path: "spec.prefix", Old: "", New: "llm-"},
+		{Path: "spec.removed", Old: "yes", Removed: true},
+	}
+	if changes := fieldChanges(existing, merged); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("fieldChanges() = %+v, expected %+v", changes, expected)
+	}
+}
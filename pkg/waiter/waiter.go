@@ -0,0 +1,285 @@
+// Package waiter waits for Kubernetes objects to become ready, or to be
+// deleted, using the kstatus poller. Unlike polling for Ready=True until a
+// timeout, it gives up as soon as an object reports that it failed.
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fluxcd/cli-utils/pkg/kstatus/polling"
+	"github.com/fluxcd/cli-utils/pkg/kstatus/polling/event"
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	"github.com/fluxcd/cli-utils/pkg/object"
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Logger reports the progress of a wait.
+type Logger interface {
+	Waitingf(format string, a ...interface{})
+}
+
+// Waiter waits for objects with the kstatus poller.
+type Waiter struct {
+	client client.Client
+	poller *polling.StatusPoller
+
+	// Interval is how often the objects are polled.
+	Interval time.Duration
+
+	// ProgressInterval is how often the progress is logged.
+	ProgressInterval time.Duration
+
+	Logger Logger
+}
+
+// New returns a Waiter polling every interval.
+func New(c client.Client, mapper apimeta.RESTMapper, interval time.Duration, logger Logger) *Waiter {
+	return &Waiter{
+		client:           c,
+		poller:           polling.NewStatusPoller(c, mapper, polling.Options{}),
+		Interval:         interval,
+		ProgressInterval: 15 * time.Second,
+		Logger:           logger,
+	}
+}
+
+// FailedError is returned when an object reports that it can't become ready.
+type FailedError struct {
+	Object  string
+	Reason  string
+	Message string
+}
+
+func (e *FailedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("%s failed: %s", e.Object, e.Message)
+	}
+	return fmt.Sprintf("%s failed (%s): %s", e.Object, e.Reason, e.Message)
+}
+
+// WaitForReady waits until all objects are ready. The wait fails as soon
+// as one of them is Stalled, Ready=False or failed by kstatus standards,
+// and when ctx is done.
+func (w *Waiter) WaitForReady(ctx context.Context, objs ...client.Object) error {
+	return w.wait(ctx, "ready", objs, ready)
+}
+
+func ready(rs *event.ResourceStatus) (bool, error) {
+	switch rs.Status {
+	case status.NotFoundStatus, status.TerminatingStatus, status.UnknownStatus:
+		return false, nil
+	case status.FailedStatus:
+		reason, message := failure(rs.Resource)
+		if message == "" {
+			message = rs.Message
+		}
+		return false, &FailedError{Object: describe(rs.Identifier), Reason: reason, Message: message}
+	}
+	if rs.Resource != nil {
+		if reason, message := failure(rs.Resource); message != "" || reason != "" {
+			return false, &FailedError{Object: describe(rs.Identifier), Reason: reason, Message: message}
+		}
+		cond := readyCondition(rs.Resource)
+		if cond != nil && cond.Status != "True" {
+			return false, nil
+		}
+		if isFluxKind(rs.Identifier.GroupKind) && (cond == nil || !observed(rs.Resource)) {
+			// kstatus reports objects with an empty status as Current,
+			// before their controller has reconciled them
+			return false, nil
+		}
+	}
+	return rs.Status == status.CurrentStatus, nil
+}
+
+// isFluxKind tells whether objects of the kind are reconciled by a Flux
+// style controller, which sets their Ready condition.
+func isFluxKind(gk schema.GroupKind) bool {
+	return gk.Group == "fluxcd.io" || strings.HasSuffix(gk.Group, ".fluxcd.io")
+}
+
+// observed tells whether the status is about the current generation.
+func observed(u *unstructured.Unstructured) bool {
+	generation, found, _ := unstructured.NestedInt64(u.Object, "status", "observedGeneration")
+	return found && generation >= u.GetGeneration()
+}
+
+// WaitForDeletion waits until all objects are gone.
+func (w *Waiter) WaitForDeletion(ctx context.Context, objs ...client.Object) error {
+	return w.wait(ctx, "deleted", objs, func(rs *event.ResourceStatus) (bool, error) {
+		return rs.Status == status.NotFoundStatus, nil
+	})
+}
+
+func (w *Waiter) wait(ctx context.Context, goal string, objs []client.Object, done func(*event.ResourceStatus) (bool, error)) error {
+	var ids object.ObjMetadataSet
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, w.client.Scheme())
+		if err != nil {
+			return err
+		}
+		ids = append(ids, object.ObjMetadata{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			GroupKind: gvk.GroupKind(),
+		})
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := w.poller.Poll(pollCtx, ids, polling.PollOptions{PollInterval: w.Interval})
+
+	start := time.Now()
+	lastProgress := start
+	pending := map[object.ObjMetadata]string{}
+	for _, id := range ids {
+		pending[id] = ""
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return w.timeoutError(ctx, start, goal, pending)
+		case e, ok := <-events:
+			if !ok {
+				// the poller stops when ctx is done
+				return w.timeoutError(ctx, start, goal, pending)
+			}
+			switch e.Type {
+			case event.ErrorEvent:
+				return e.Error
+			case event.ResourceUpdateEvent:
+				rs := e.Resource
+				if _, ok := pending[rs.Identifier]; !ok {
+					continue
+				}
+				if rs.Error != nil && rs.Status != status.NotFoundStatus {
+					pending[rs.Identifier] = rs.Error.Error()
+					continue
+				}
+				finished, err := done(rs)
+				if err != nil {
+					return err
+				}
+				if finished {
+					delete(pending, rs.Identifier)
+					if len(pending) == 0 {
+						return nil
+					}
+					continue
+				}
+				pending[rs.Identifier] = rs.Message
+			}
+
+			if w.Logger != nil && time.Since(lastProgress) >= w.ProgressInterval {
+				lastProgress = time.Now()
+				w.Logger.Waitingf("still waiting for %s to be %s (%s elapsed)", describePending(pending), goal, time.Since(start).Round(time.Second))
+			}
+		}
+	}
+}
+
+func (w *Waiter) timeoutError(ctx context.Context, start time.Time, goal string, pending map[object.ObjMetadata]string) error {
+	var details []string
+	for id, message := range pending {
+		if message != "" {
+			details = append(details, describe(id)+": "+message)
+		}
+	}
+	err := fmt.Errorf("timed out after %s waiting for %s to be %s", time.Since(start).Round(time.Second), describePending(pending), goal)
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("interrupted after %s waiting for %s to be %s", time.Since(start).Round(time.Second), describePending(pending), goal)
+	}
+	if len(details) > 0 {
+		err = fmt.Errorf("%w, last status: %s", err, strings.Join(details, "; "))
+	}
+	return err
+}
+
+// failure returns the reason and message of a Stalled=True condition, or of
+// a Ready=False condition about the current generation that doesn't just
+// say that the reconciliation is in progress.
+func failure(u *unstructured.Unstructured) (string, string) {
+	if u == nil {
+		return "", ""
+	}
+	conditions := conditionsOf(u)
+	if cond := apimeta.FindStatusCondition(conditions, fluxmeta.StalledCondition); cond != nil && cond.Status == "True" {
+		return cond.Reason, cond.Message
+	}
+
+	cond := apimeta.FindStatusCondition(conditions, fluxmeta.ReadyCondition)
+	if cond == nil || cond.Status != "False" {
+		return "", ""
+	}
+	switch cond.Reason {
+	case fluxmeta.ProgressingReason, fluxmeta.ProgressingWithRetryReason, "DependencyNotReady":
+		return "", ""
+	}
+	observed, found, _ := unstructured.NestedInt64(u.Object, "status", "observedGeneration")
+	if found && observed != u.GetGeneration() {
+		// about a previous generation
+		return "", ""
+	}
+	if cond.ObservedGeneration != 0 && cond.ObservedGeneration != u.GetGeneration() {
+		return "", ""
+	}
+	return cond.Reason, cond.Message
+}
+
+func readyCondition(u *unstructured.Unstructured) *metav1.Condition {
+	return apimeta.FindStatusCondition(conditionsOf(u), fluxmeta.ReadyCondition)
+}
+
+func conditionsOf(u *unstructured.Unstructured) []metav1.Condition {
+	items, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	var conditions []metav1.Condition
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var cond metav1.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &cond); err != nil {
+			// conditions of core types such as Deployments lack some fields
+			cond.Type, _ = m["type"].(string)
+			s, _ := m["status"].(string)
+			cond.Status = metav1.ConditionStatus(s)
+			cond.Reason, _ = m["reason"].(string)
+			cond.Message, _ = m["message"].(string)
+		}
+		conditions = append(conditions, cond)
+	}
+	return conditions
+}
+
+func describe(id object.ObjMetadata) string {
+	if id.Namespace == "" {
+		return fmt.Sprintf("%s/%s", id.GroupKind.Kind, id.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", id.GroupKind.Kind, id.Namespace, id.Name)
+}
+
+func describePending(pending map[object.ObjMetadata]string) string {
+	var names []string
+	for id := range pending {
+		names = append(names, describe(id))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,107 @@
+package waiter
+
+import (
+	"testing"
+
+	"github.com/fluxcd/cli-utils/pkg/kstatus/polling/event"
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	"github.com/fluxcd/cli-utils/pkg/object"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newObject(generation, observed int64, conditions ...map[string]interface{}) *unstructured.Unstructured {
+	var items []interface{}
+	for _, c := range conditions {
+		items = append(items, c)
+	}
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"observedGeneration": observed,
+			"conditions":         items,
+		},
+	}}
+	u.SetGeneration(generation)
+	return u
+}
+
+func condition(t, status, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":               t,
+		"status":             status,
+		"reason":             reason,
+		"message":            reason + " message",
+		"lastTransitionTime": "2024-01-01T00:00:00Z",
+	}
+}
+
+func TestFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		obj    *unstructured.Unstructured
+		reason string
+	}{
+		{"no conditions", newObject(1, 1), ""},
+		{"ready", newObject(1, 1, condition("Ready", "True", "Succeeded")), ""},
+		{"progressing", newObject(1, 1, condition("Ready", "False", "Progressing")), ""},
+		{"retrying", newObject(1, 1, condition("Ready", "False", "ProgressingWithRetry")), ""},
+		{"failed", newObject(1, 1, condition("Ready", "False", "OCIArtifactPullFailed")), "OCIArtifactPullFailed"},
+		{"failed previous generation", newObject(2, 1, condition("Ready", "False", "OCIArtifactPullFailed")), ""},
+		{"stalled", newObject(2, 1, condition("Stalled", "True", "InvalidURL")), "InvalidURL"},
+		{"deployment", newObject(1, 1, map[string]interface{}{
+			"type":           "Available",
+			"status":         "False",
+			"reason":         "MinimumReplicasUnavailable",
+			"lastUpdateTime": "2024-01-01T00:00:00Z",
+		}), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, _ := failure(tt.obj)
+			if reason != tt.reason {
+				t.Fatalf("failure() reason = %q, want %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestReady(t *testing.T) {
+	languageModel := schema.GroupKind{Group: "ai.contrib.fluxcd.io", Kind: "LanguageModel"}
+	deployment := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	fresh := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	fresh.SetGeneration(1)
+
+	tests := []struct {
+		name     string
+		kind     schema.GroupKind
+		status   status.Status
+		obj      *unstructured.Unstructured
+		expected bool
+		failed   bool
+	}{
+		{"created", languageModel, status.CurrentStatus, fresh, false, false},
+		{"not observed", languageModel, status.CurrentStatus, newObject(1, 0), false, false},
+		{"ready", languageModel, status.CurrentStatus, newObject(1, 1, condition("Ready", "True", "Succeeded")), true, false},
+		{"ready previous generation", languageModel, status.CurrentStatus, newObject(2, 1, condition("Ready", "True", "Succeeded")), false, false},
+		{"progressing", languageModel, status.InProgressStatus, newObject(1, 1, condition("Ready", "False", "Progressing")), false, false},
+		{"failed", languageModel, status.CurrentStatus, newObject(1, 1, condition("Ready", "False", "OCIArtifactPullFailed")), false, true},
+		{"deployment", deployment, status.CurrentStatus, newObject(1, 1), true, false},
+		{"deployment in progress", deployment, status.InProgressStatus, newObject(2, 1), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &event.ResourceStatus{
+				Identifier: object.ObjMetadata{Namespace: "default", Name: "test", GroupKind: tt.kind},
+				Status:     tt.status,
+				Resource:   tt.obj,
+			}
+			finished, err := ready(rs)
+			if (err != nil) != tt.failed {
+				t.Fatalf("ready() error = %v, want failed %v", err, tt.failed)
+			}
+			if finished != tt.expected {
+				t.Fatalf("ready() = %v, want %v", finished, tt.expected)
+			}
+		})
+	}
+}